@@ -18,7 +18,11 @@ import (
 	ort "github.com/yalue/onnxruntime_go"
 
 	"github.com/your-org/fd/internal/api"
+	"github.com/your-org/fd/internal/api/handlers"
+	"github.com/your-org/fd/internal/api/live"
 	"github.com/your-org/fd/internal/api/ws"
+	"github.com/your-org/fd/internal/auth"
+	"github.com/your-org/fd/internal/clustering"
 	"github.com/your-org/fd/internal/config"
 	"github.com/your-org/fd/internal/models"
 	"github.com/your-org/fd/internal/observability"
@@ -88,31 +92,106 @@ func main() {
 	defer cancel()
 
 	err = consumer.ConsumeEvents(ctx, "api-events", func(ctx context.Context, msg jetstream.Msg) error {
+		if queue.IsAnalyticsEventSubject(msg.Subject()) {
+			var ev models.AnalyticsEvent
+			if err := json.Unmarshal(msg.Data(), &ev); err != nil {
+				return err
+			}
+			if err := db.AddAnalyticsCounts(ctx, &ev); err != nil {
+				slog.Error("store analytics", "error", err, "stream_id", ev.StreamID)
+			}
+			return nil
+		}
+
+		if queue.IsTrackEventSubject(msg.Subject()) {
+			var ev models.TrackEvent
+			if err := json.Unmarshal(msg.Data(), &ev); err != nil {
+				return err
+			}
+
+			track, err := db.UpsertTrack(ctx, &ev)
+			if err != nil {
+				slog.Error("store track", "error", err, "track", ev.TrackID)
+				return nil
+			}
+
+			hub.BroadcastEvent(&dto.WSEvent{
+				Type:     string(ev.Type),
+				StreamID: ev.StreamID,
+				Track: &dto.TrackResponse{
+					ID:               track.ID,
+					StreamID:         track.StreamID,
+					TrackID:          track.TrackID,
+					Status:           string(track.Status),
+					FirstSeen:        track.FirstSeen.Format(time.RFC3339),
+					LastSeen:         track.LastSeen.Format(time.RFC3339),
+					DwellSeconds:     track.DwellSeconds,
+					FrameCount:       track.FrameCount,
+					PersonID:         track.PersonID,
+					MatchScore:       track.MatchScore,
+					Gender:           track.Gender,
+					GenderConfidence: track.GenderConfidence,
+					Age:              track.Age,
+					AgeRange:         track.AgeRange,
+					AttributeSamples: track.AttributeSamples,
+				},
+			})
+			return nil
+		}
+
 		var result models.DetectionResult
 		if err := json.Unmarshal(msg.Data(), &result); err != nil {
 			return err
 		}
 
 		// Store event in DB
+		landmarks := make([]float32, 0, 2*len(result.Landmarks))
+		for _, p := range result.Landmarks {
+			landmarks = append(landmarks, p[0], p[1])
+		}
 		event := &models.Event{
 			StreamID:         result.StreamID,
 			TrackID:          result.TrackID,
 			Timestamp:        result.Timestamp,
+			BBox:             result.BBox[:],
+			Landmarks:        landmarks,
 			Gender:           result.Gender,
 			GenderConfidence: result.GenderConfidence,
 			Age:              result.Age,
 			AgeRange:         result.AgeRange,
+			AttributeSamples: result.AttributeSamples,
 			Confidence:       result.Confidence,
 			Embedding:        result.Embedding,
+			ModelVersion:     result.ModelVersion,
 			MatchedPersonID:  result.MatchedPersonID,
 			MatchScore:       result.MatchScore,
+			InstantPersonID:  result.InstantPersonID,
+			InstantScore:     result.InstantScore,
 			SnapshotKey:      result.SnapshotKey,
 			FrameKey:         result.FrameKey,
+			QualityScore:     result.QualityScore,
+			LivenessScore:    result.LivenessScore,
+			Spoof:            result.Spoof,
+			Attributes:       result.Attributes,
+			Pose:             &result.Pose,
 		}
 		if err := db.CreateEvent(ctx, event); err != nil {
 			slog.Error("store event", "error", err)
 		}
 
+		// A better shot replaces the snapshot of the track's earlier events,
+		// whose old snapshot is then deleted
+		if result.BestShot && result.SnapshotKey != "" {
+			superseded, err := db.SetTrackSnapshot(ctx, result.StreamID, result.TrackID, result.SnapshotKey)
+			if err != nil {
+				slog.Error("update track snapshot", "error", err, "track", result.TrackID)
+			} else if len(superseded) > 0 {
+				if err := minioStore.DeleteObjects(ctx, superseded); err != nil {
+					slog.Warn("delete superseded snapshots", "error", err, "track", result.TrackID)
+				}
+			}
+		}
+
 		// Broadcast via WebSocket
 		evtType := "face_detected"
 		if result.MatchedPersonID != nil {
@@ -122,18 +201,29 @@ func main() {
 		hub.BroadcastEvent(&dto.WSEvent{
 			Type:     evtType,
 			StreamID: result.StreamID,
-			Data: dto.EventResponse{
+			Data: &dto.EventResponse{
 				ID:               event.ID,
 				StreamID:         event.StreamID,
 				TrackID:          event.TrackID,
 				Timestamp:        event.Timestamp.Format(time.RFC3339),
+				BBox:             event.BBox,
+				Landmarks:        event.Landmarks,
 				Gender:           event.Gender,
 				GenderConfidence: event.GenderConfidence,
 				Age:              event.Age,
 				AgeRange:         event.AgeRange,
+				AttributeSamples: event.AttributeSamples,
 				Confidence:       event.Confidence,
 				MatchedPersonID:  event.MatchedPersonID,
 				MatchScore:       event.MatchScore,
+				InstantPersonID:  event.InstantPersonID,
+				InstantScore:     event.InstantScore,
+				QualityScore:     event.QualityScore,
+				ModelVersion:     event.ModelVersion,
+				LivenessScore:    event.LivenessScore,
+				Spoof:            event.Spoof,
+				Attributes:       handlers.AttributesToResponse(event.Attributes),
+				Pose:             (*dto.HeadPose)(event.Pose),
 				SnapshotURL:      "/v1/events/" + event.ID.String() + "/snapshot",
 				CreatedAt:        event.CreatedAt.Format(time.RFC3339),
 			},
@@ -145,8 +235,24 @@ func main() {
 		slog.Warn("start event consumer", "error", err)
 	}
 
+	// Latest processed frame of each stream for live previews
+	liveFeed := live.NewFeed()
+	if err := consumer.SubscribeLive(func(data []byte) {
+		var frame models.LiveFrame
+		if err := json.Unmarshal(data, &frame); err != nil {
+			slog.Warn("decode live frame", "error", err)
+			return
+		}
+		liveFeed.Publish(&frame)
+	}); err != nil {
+		slog.Warn("subscribe live frames", "error", err)
+	}
+
 	// Initialize ONNX Runtime for face embedding (AddFace / Search endpoints)
-	var embedFn func([]byte) ([]float32, float32, error)
+	var embedFn func([]byte) ([]float32, models.FaceQuality, error)
+	var detectFn func([]byte) ([][4]float32, error)
+	var modelVersion string
+	var reembed handlers.ReembedJob
 
 	ort.SetSharedLibraryPath(getONNXLibPath())
 	if err := ort.InitializeEnvironment(); err != nil {
@@ -157,20 +263,57 @@ func main() {
 			slog.Warn("vision pipeline init failed — AddFace/Search will be unavailable", "error", err)
 		} else {
 			embedFn = pipeline.EmbedImage
+			detectFn = pipeline.DetectFaces
+			modelVersion = pipeline.ModelVersion()
 			defer pipeline.Close()
 			defer ort.DestroyEnvironment()
-			slog.Info("vision pipeline ready for API (AddFace/Search)")
+			slog.Info("vision pipeline ready for API (AddFace/Search)", "model_version", modelVersion)
+
+			// Enrollment faces embedded by another model are not searchable until re-embedded
+			reembedder := vision.NewReembedder(ctx, pipeline)
+			reembed = reembedder
+			if n, err := db.CountStaleFaceEmbeddings(ctx, modelVersion); err != nil {
+				slog.Warn("count stale face embeddings", "error", err)
+			} else if n > 0 {
+				slog.Info("enrollment faces from another embedding model, re-embedding", "count", n)
+				reembedder.Start()
+			}
+		}
+	}
+
+	// Group unknown tracks into anonymous visitors
+	if cfg.Clustering.Interval > 0 {
+		go clustering.NewClusterer(db, cfg.Clustering).Run(ctx)
+	}
+
+	// Further API keys, possibly restricted to redacted frames and snapshots
+	var apiKeys []auth.Key
+	for i, k := range cfg.Server.APIKeys {
+		if k.Key == "" {
+			slog.Error("server.api_keys entry without a key", "index", i)
+			os.Exit(1)
 		}
+		apiKeys = append(apiKeys, auth.Key{Value: k.Key, Redaction: models.ParseRedactionMode(k.Redaction)})
 	}
 
 	// Setup router
 	router := api.NewRouter(api.RouterConfig{
-		APIKey:   cfg.Server.APIKey,
-		DB:       db,
-		MinIO:    minioStore,
-		Producer: producer,
-		Hub:      hub,
-		EmbedFn:  embedFn,
+		APIKey:           cfg.Server.APIKey,
+		APIKeys:          apiKeys,
+		DB:               db,
+		MinIO:            minioStore,
+		Producer:         producer,
+		Hub:              hub,
+		Live:             liveFeed,
+		LiveMaxFPS:       cfg.Server.LiveMaxFPS,
+		EmbedFn:          embedFn,
+		DetectFn:         detectFn,
+		Redaction:        models.ParseRedactionMode(cfg.Vision.Redaction),
+		RedactionStyle:   models.RedactionStyle(cfg.Vision.RedactionStyle),
+		EnrollMinQuality: cfg.Vision.EnrollMinQuality,
+		ModelVersion:     modelVersion,
+		Reembed:          reembed,
+		Clustering:       cfg.Clustering,
 	})
 
 	// Start HTTP server
@@ -113,6 +113,27 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Optionally merge the embedding/attribute work of all workers into shared batches
+	if cfg.Vision.BatchWindow > 0 {
+		batcher, err := vision.NewBatcher(cfg.Vision)
+		if err != nil {
+			slog.Error("init inference batcher", "error", err)
+			os.Exit(1)
+		}
+		batcherDone := make(chan struct{})
+		go func() {
+			defer close(batcherDone)
+			batcher.Run(ctx)
+		}()
+		defer func() {
+			<-batcherDone
+			batcher.Close()
+		}()
+		for _, p := range pipelines {
+			p.UseBatcher(batcher)
+		}
+	}
+
 	// Each worker goroutine gets its own pipeline via workerID index — no sharing.
 	err = consumer.ConsumeFramesWithIndex(ctx, "vision-workers", func(ctx context.Context, msg jetstream.Msg, workerID int) error {
 		var task models.FrameTask
@@ -132,6 +153,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	// End tracks of streams that stopped sending frames
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, p := range pipelines {
+					p.SweepIdle(ctx, cfg.Tracking.IdleTimeout)
+				}
+			}
+		}
+	}()
+
 	// Metrics endpoint
 	go func() {
 		mux := http.NewServeMux()
@@ -13,6 +13,7 @@ require (
 	github.com/pgvector/pgvector-go v0.2.2
 	github.com/prometheus/client_golang v1.20.5
 	github.com/yalue/onnxruntime_go v1.25.0
+	golang.org/x/image v0.18.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
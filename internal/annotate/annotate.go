@@ -0,0 +1,94 @@
+// Package annotate draws face boxes, landmarks and labels onto frames, so
+// operators can see which face a result refers to.
+package annotate
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	knownColor   = color.RGBA{0, 200, 80, 255}  // matched person
+	unknownColor = color.RGBA{255, 150, 0, 255} // anyone else
+	textColor    = color.RGBA{255, 255, 255, 255}
+)
+
+// Face is one face to draw.
+type Face struct {
+	BBox      [4]float32   // x1, y1, x2, y2
+	Landmarks [][2]float32 // optional
+	Known     bool         // matched person: drawn in green rather than orange
+	Highlight bool         // drawn with thicker lines, e.g. the face an event is about
+	Labels    []string     // text lines drawn above the box
+}
+
+// Faces returns a copy of img with the faces drawn on it.
+func Faces(img image.Image, faces []Face) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+
+	// Lines stay visible on large frames without swamping small ones
+	thick := max(b.Dx()/400, 2)
+	for _, f := range faces {
+		c := unknownColor
+		if f.Known {
+			c = knownColor
+		}
+		r := image.Rect(int(f.BBox[0]), int(f.BBox[1]), int(f.BBox[2]), int(f.BBox[3]))
+		if f.Highlight {
+			strokeRect(dst, r.Inset(-thick), 3*thick, c)
+		}
+		strokeRect(dst, r, thick, c)
+		for _, p := range f.Landmarks {
+			x, y := int(p[0]), int(p[1])
+			fillRect(dst, image.Rect(x-thick, y-thick, x+thick+1, y+thick+1), c)
+		}
+		drawLabels(dst, r, f.Labels, c)
+	}
+	return dst
+}
+
+// drawLabels writes lines on a filled band above box, or below its top edge
+// when there is no room above.
+func drawLabels(img *image.RGBA, box image.Rectangle, lines []string, bg color.Color) {
+	if len(lines) == 0 {
+		return
+	}
+	face := basicfont.Face7x13
+	const pad = 2
+	lineH := face.Metrics().Height.Ceil()
+
+	w := 0
+	for _, l := range lines {
+		w = max(w, font.MeasureString(face, l).Ceil())
+	}
+	band := image.Rect(0, 0, w+2*pad, len(lines)*lineH+2*pad)
+	band = band.Add(image.Pt(box.Min.X, box.Min.Y-band.Dy()))
+	if band.Min.Y < img.Bounds().Min.Y {
+		band = band.Add(image.Pt(0, box.Min.Y-band.Min.Y))
+	}
+	fillRect(img, band, bg)
+
+	d := font.Drawer{Dst: img, Src: image.NewUniform(textColor), Face: face}
+	for i, l := range lines {
+		d.Dot = fixed.P(band.Min.X+pad, band.Min.Y+pad+i*lineH+face.Metrics().Ascent.Ceil())
+		d.DrawString(l)
+	}
+}
+
+func strokeRect(img *image.RGBA, r image.Rectangle, thick int, c color.Color) {
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+thick), c)
+	fillRect(img, image.Rect(r.Min.X, r.Max.Y-thick, r.Max.X, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+thick, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Max.X-thick, r.Min.Y, r.Max.X, r.Max.Y), c)
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r.Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, draw.Src)
+}
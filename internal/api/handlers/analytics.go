@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/clustering"
+	"github.com/your-org/fd/internal/models"
+	"github.com/your-org/fd/internal/storage"
+	"github.com/your-org/fd/pkg/dto"
+)
+
+// maxAnalyticsBuckets bounds the number of buckets per counter in one response.
+const maxAnalyticsBuckets = 5000
+
+type AnalyticsHandler struct {
+	db *storage.PostgresStore
+	// Footfall counts unique visitors for the Visitors endpoint.
+	Footfall *clustering.Footfall
+}
+
+func NewAnalyticsHandler(db *storage.PostgresStore) *AnalyticsHandler {
+	return &AnalyticsHandler{db: db}
+}
+
+// Stream returns the tripwire and zone counts of a stream.
+// Optional query params: from, to (RFC3339, default the last 24h), interval
+// (Go duration, whole minutes, default 1h).
+func (h *AnalyticsHandler) Stream(c *gin.Context) {
+	streamID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stream id"})
+		return
+	}
+
+	from, to, ok := parseWindow(c, 24*time.Hour)
+	if !ok {
+		return
+	}
+
+	interval, err := time.ParseDuration(c.DefaultQuery("interval", "1h"))
+	if err != nil || interval < time.Minute || interval%time.Minute != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be a whole number of minutes, e.g. 15m or 1h"})
+		return
+	}
+	if to.Sub(from)/interval > maxAnalyticsBuckets {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many buckets, use a larger interval or a shorter window"})
+		return
+	}
+
+	st, err := h.db.GetStream(c.Request.Context(), streamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if st == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "stream not found"})
+		return
+	}
+
+	buckets, err := h.db.QueryAnalytics(c.Request.Context(), streamID, from, to, interval)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := dto.StreamAnalyticsResponse{
+		StreamID: streamID,
+		From:     from.Format(time.RFC3339),
+		To:       to.Format(time.RFC3339),
+		Interval: interval.String(),
+		Lines:    []dto.LineAnalytics{},
+		Zones:    []dto.ZoneAnalytics{},
+	}
+
+	// Configured counters come first and are listed even without activity;
+	// counters since removed from the config follow with their history.
+	lineIdx := make(map[string]int)
+	zoneIdx := make(map[string]int)
+	addLine := func(name string) int {
+		if i, ok := lineIdx[name]; ok {
+			return i
+		}
+		lineIdx[name] = len(resp.Lines)
+		resp.Lines = append(resp.Lines, dto.LineAnalytics{Name: name, Buckets: []dto.LineBucket{}})
+		return lineIdx[name]
+	}
+	addZone := func(name string) int {
+		if i, ok := zoneIdx[name]; ok {
+			return i
+		}
+		zoneIdx[name] = len(resp.Zones)
+		resp.Zones = append(resp.Zones, dto.ZoneAnalytics{Name: name, Buckets: []dto.ZoneBucket{}})
+		return zoneIdx[name]
+	}
+	if settings, err := models.ParseStreamSettings(st.Config); err == nil && settings != nil {
+		for _, l := range settings.Tripwires {
+			addLine(l.Name)
+		}
+		for _, z := range settings.Zones {
+			addZone(z.Name)
+		}
+	}
+
+	for _, b := range buckets {
+		start := b.Bucket.UTC().Format(time.RFC3339)
+		switch b.Kind {
+		case models.AnalyticsLine:
+			l := &resp.Lines[addLine(b.Name)]
+			l.In += b.In
+			l.Out += b.Out
+			l.Buckets = append(l.Buckets, dto.LineBucket{Start: start, In: b.In, Out: b.Out})
+		case models.AnalyticsZone:
+			z := &resp.Zones[addZone(b.Name)]
+			z.Entries += b.In
+			z.Exits += b.Out
+			z.Occupancy = b.Occupancy // buckets are in time order
+			z.PeakOccupancy = max(z.PeakOccupancy, b.PeakOccupancy)
+			z.Buckets = append(z.Buckets, dto.ZoneBucket{
+				Start:         start,
+				Entries:       b.In,
+				Exits:         b.Out,
+				Occupancy:     b.Occupancy,
+				PeakOccupancy: b.PeakOccupancy,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Visitors returns the unique visitors per hour or day, de-duplicated across
+// tracks and cameras, and the anonymous visitors that came back on several days.
+// Optional query params: stream_id (default all streams), from, to (RFC3339,
+// default the last 7 days), interval (hour or day, default day), tz (IANA time
+// zone of the buckets, default UTC), min_days (default 2), limit (returning
+// visitors, default 50).
+func (h *AnalyticsHandler) Visitors(c *gin.Context) {
+	var streamID *uuid.UUID
+	if sid := c.Query("stream_id"); sid != "" {
+		id, err := uuid.Parse(sid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stream_id"})
+			return
+		}
+		streamID = &id
+	}
+
+	from, to, ok := parseWindow(c, 7*24*time.Hour)
+	if !ok {
+		return
+	}
+
+	unit, loc, ok := parseCalendarBuckets(c, from, to)
+	if !ok {
+		return
+	}
+
+	minDays, err := strconv.Atoi(c.DefaultQuery("min_days", "2"))
+	if err != nil || minDays < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_days must be a positive integer"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if streamID != nil {
+		st, err := h.db.GetStream(c.Request.Context(), *streamID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if st == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "stream not found"})
+			return
+		}
+	}
+
+	buckets, err := h.Footfall.Buckets(c.Request.Context(), streamID, from, to, unit, loc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	returning, err := h.db.ReturningVisitors(c.Request.Context(), streamID, from, to, loc.String(), minDays, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := dto.VisitorAnalyticsResponse{
+		StreamID:  streamID,
+		From:      from.Format(time.RFC3339),
+		To:        to.Format(time.RFC3339),
+		Interval:  unit,
+		Timezone:  loc.String(),
+		Buckets:   make([]dto.FootfallBucket, 0, len(buckets)),
+		MinDays:   minDays,
+		Returning: make([]dto.ReturningVisitor, 0, len(returning)),
+	}
+	for _, b := range buckets {
+		resp.Buckets = append(resp.Buckets, dto.FootfallBucket{
+			Start:          b.Start.Format(time.RFC3339),
+			UniqueVisitors: b.UniqueVisitors,
+			Known:          b.Known,
+			Unknown:        b.Unknown,
+			Tracks:         b.Tracks,
+		})
+	}
+	for _, v := range returning {
+		resp.Returning = append(resp.Returning, dto.ReturningVisitor{
+			VisitorID: v.VisitorID,
+			PersonID:  v.PersonID,
+			Days:      v.Days,
+			Tracks:    v.Tracks,
+			FirstSeen: v.FirstSeen.UTC().Format(time.RFC3339),
+			LastSeen:  v.LastSeen.UTC().Format(time.RFC3339),
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Attributes returns per hour or day how many tracks a face attribute
+// classifier gave each label, e.g. mask compliance with name=mask. A track
+// counts once per bucket, with its latest label there.
+// Query params: name (required), stream_id (default all streams), from, to
+// (RFC3339, default the last 7 days), interval (hour or day, default day), tz
+// (IANA time zone of the buckets, default UTC).
+func (h *AnalyticsHandler) Attributes(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	var streamID *uuid.UUID
+	if sid := c.Query("stream_id"); sid != "" {
+		id, err := uuid.Parse(sid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stream_id"})
+			return
+		}
+		streamID = &id
+	}
+
+	from, to, ok := parseWindow(c, 7*24*time.Hour)
+	if !ok {
+		return
+	}
+	unit, loc, ok := parseCalendarBuckets(c, from, to)
+	if !ok {
+		return
+	}
+
+	buckets, err := h.db.QueryAttributeCounts(c.Request.Context(), streamID, name, from, to, unit, loc.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := dto.AttributeAnalyticsResponse{
+		Name:     name,
+		StreamID: streamID,
+		From:     from.Format(time.RFC3339),
+		To:       to.Format(time.RFC3339),
+		Interval: unit,
+		Timezone: loc.String(),
+		Totals:   map[string]int{},
+		Buckets:  []dto.AttributeBucket{},
+	}
+	for _, b := range buckets {
+		start := b.Bucket.In(loc).Format(time.RFC3339)
+		if n := len(resp.Buckets); n == 0 || resp.Buckets[n-1].Start != start {
+			resp.Buckets = append(resp.Buckets, dto.AttributeBucket{Start: start, Tracks: map[string]int{}})
+		}
+		resp.Buckets[len(resp.Buckets)-1].Tracks[b.Label] = b.Tracks
+		resp.Totals[b.Label] += b.Tracks
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseCalendarBuckets reads the interval (hour or day) and tz query params.
+// On bad values it responds with 400 and returns false.
+func parseCalendarBuckets(c *gin.Context, from, to time.Time) (unit string, loc *time.Location, ok bool) {
+	unit = c.DefaultQuery("interval", clustering.UnitDay)
+	if unit != clustering.UnitHour && unit != clustering.UnitDay {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be hour or day"})
+		return "", nil, false
+	}
+	tz := c.DefaultQuery("tz", "UTC")
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown tz"})
+		return "", nil, false
+	}
+	span := to.Sub(from)
+	if (unit == clustering.UnitHour && span/time.Hour > maxAnalyticsBuckets) ||
+		(unit == clustering.UnitDay && span/(24*time.Hour) > maxAnalyticsBuckets) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many buckets, use a larger interval or a shorter window"})
+		return "", nil, false
+	}
+	return unit, loc, true
+}
+
+// parseWindow reads the from and to query params (RFC3339), defaulting to the
+// span up to now. On a bad window it responds with 400 and returns false.
+func parseWindow(c *gin.Context, span time.Duration) (from, to time.Time, ok bool) {
+	to = time.Now().UTC()
+	if toStr := c.Query("to"); toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be RFC3339"})
+			return from, to, false
+		}
+		to = t
+	}
+	from = to.Add(-span)
+	if fromStr := c.Query("from"); fromStr != "" {
+		t, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be RFC3339"})
+			return from, to, false
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return from, to, false
+	}
+	return from, to, true
+}
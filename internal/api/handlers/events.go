@@ -1,14 +1,25 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/jpeg"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/your-org/fd/internal/annotate"
+	"github.com/your-org/fd/internal/api/live"
+	"github.com/your-org/fd/internal/auth"
+	"github.com/your-org/fd/internal/models"
+	"github.com/your-org/fd/internal/redact"
 	"github.com/your-org/fd/internal/storage"
 	"github.com/your-org/fd/pkg/dto"
 )
@@ -16,7 +27,19 @@ import (
 type EventHandler struct {
 	db      *storage.PostgresStore
 	minio   *storage.MinIOStore
-	EmbedFn func(imageData []byte) ([]float32, float32, error)
+	EmbedFn func(imageData []byte) ([]float32, models.FaceQuality, error)
+	// ModelVersion is the version of the embedder behind EmbedFn.
+	ModelVersion string
+	// DetectFn finds the face boxes of a JPEG frame for redacting it on the fly
+	// (nil without a vision pipeline: redacted frames are then unavailable).
+	DetectFn func(imageData []byte) ([][4]float32, error)
+	// Redaction and RedactionStyle are the global defaults of the stream settings.
+	Redaction      models.RedactionMode
+	RedactionStyle models.RedactionStyle
+	// LiveFeed holds the latest processed frames for the Live preview, at most
+	// LiveMaxFPS frames per second each.
+	LiveFeed   *live.Feed
+	LiveMaxFPS float64
 }
 
 func NewEventHandler(db *storage.PostgresStore, minio *storage.MinIOStore) *EventHandler {
@@ -58,7 +81,31 @@ func (h *EventHandler) List(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	events, total, err := h.db.QueryEvents(c.Request.Context(), streamID, from, to, personID, unknown, limit, offset)
+	// attribute=mask:none&attribute=eyewear:sunglasses
+	var attributes map[string]string
+	for _, f := range c.QueryArray("attribute") {
+		name, label, ok := strings.Cut(f, ":")
+		if !ok || name == "" || label == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "attribute must be name:label, e.g. mask:mask"})
+			return
+		}
+		if attributes == nil {
+			attributes = make(map[string]string)
+		}
+		attributes[name] = label
+	}
+
+	// max_yaw=30&max_pitch=20 keeps near-frontal faces only
+	maxYaw, ok := queryDegrees(c, "max_yaw")
+	if !ok {
+		return
+	}
+	maxPitch, ok := queryDegrees(c, "max_pitch")
+	if !ok {
+		return
+	}
+
+	events, total, err := h.db.QueryEvents(c.Request.Context(), streamID, from, to, personID, unknown, attributes, maxYaw, maxPitch, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -71,13 +118,25 @@ func (h *EventHandler) List(c *gin.Context) {
 			StreamID:         ev.StreamID,
 			TrackID:          ev.TrackID,
 			Timestamp:        ev.Timestamp.Format(time.RFC3339),
+			BBox:             ev.BBox,
+			Landmarks:        ev.Landmarks,
 			Gender:           ev.Gender,
 			GenderConfidence: ev.GenderConfidence,
 			Age:              ev.Age,
 			AgeRange:         ev.AgeRange,
+			AttributeSamples: ev.AttributeSamples,
 			Confidence:       ev.Confidence,
 			MatchedPersonID:  ev.MatchedPersonID,
 			MatchScore:       ev.MatchScore,
+			InstantPersonID:  ev.InstantPersonID,
+			InstantScore:     ev.InstantScore,
+			QualityScore:     ev.QualityScore,
+			ModelVersion:     ev.ModelVersion,
+			VisitorID:        ev.VisitorID,
+			LivenessScore:    ev.LivenessScore,
+			Spoof:            ev.Spoof,
+			Attributes:       AttributesToResponse(ev.Attributes),
+			Pose:             (*dto.HeadPose)(ev.Pose),
 			CreatedAt:        ev.CreatedAt.Format(time.RFC3339),
 		}
 		if ev.SnapshotKey != "" {
@@ -140,7 +199,7 @@ func (h *EventHandler) SearchEvents(c *gin.Context) {
 		}
 	}
 
-	matches, err := h.db.SearchEvents(c.Request.Context(), embedding, streamID, threshold, limit)
+	matches, err := h.db.SearchEvents(c.Request.Context(), embedding, h.ModelVersion, streamID, threshold, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -198,7 +257,7 @@ func (h *EventHandler) SimilarByTrack(c *gin.Context) {
 	}
 
 	// Get embedding from the best event of this track
-	embedding, err := h.db.GetEmbeddingByTrackID(c.Request.Context(), streamID, trackID)
+	embedding, modelVersion, err := h.db.GetEmbeddingByTrackID(c.Request.Context(), streamID, trackID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -208,8 +267,9 @@ func (h *EventHandler) SimilarByTrack(c *gin.Context) {
 		return
 	}
 
-	// Search similar events across all streams (or pass nil for no stream filter)
-	matches, err := h.db.SearchEvents(c.Request.Context(), embedding, nil, threshold, limit)
+	// Search similar events across all streams (or pass nil for no stream filter),
+	// among events embedded by the same model as the track
+	matches, err := h.db.SearchEvents(c.Request.Context(), embedding, modelVersion, nil, threshold, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -236,7 +296,11 @@ func (h *EventHandler) SimilarByTrack(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"results": results, "total": len(results)})
 }
 
-// Frame proxies the full source frame image from MinIO.
+// Frame proxies the full source frame image from MinIO. Faces are obscured
+// on the fly as the stream's redaction, the caller's API key or the redact
+// query param (off, unknown or all) require, whichever is strictest. With
+// annotate=true the boxes, landmarks, track IDs, matches and age/gender of the
+// frame's faces are drawn on it, the event's own face highlighted.
 func (h *EventHandler) Frame(c *gin.Context) {
 	eventID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -255,16 +319,63 @@ func (h *EventHandler) Frame(c *gin.Context) {
 		return
 	}
 
-	data, err := h.minio.GetObject(c.Request.Context(), ev.FrameKey)
+	h.serveFrame(c, ev.StreamID, ev.FrameKey, ev.TrackID)
+}
+
+// LatestFrame proxies the frame of a stream's most recent event, with the same
+// redact and annotate query params as Frame.
+func (h *EventHandler) LatestFrame(c *gin.Context) {
+	streamID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stream id"})
+		return
+	}
+
+	key, err := h.db.LatestFrameKey(c.Request.Context(), streamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if key == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no frame for this stream"})
+		return
+	}
+
+	h.serveFrame(c, streamID, key, "")
+}
+
+// serveFrame responds with a stored frame, redacted and annotated as the
+// request asks. The face of highlightTrack, if any, is drawn emphasized.
+func (h *EventHandler) serveFrame(c *gin.Context, streamID uuid.UUID, frameKey, highlightTrack string) {
+	mode, style, ok := h.redaction(c, streamID)
+	if !ok {
+		return
+	}
+	annotateStr := c.Query("annotate")
+	annotated := annotateStr == "true" || annotateStr == "1"
+
+	data, err := h.minio.GetObject(c.Request.Context(), frameKey)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "frame not found"})
 		return
 	}
 
+	if mode != models.RedactionOff && h.DetectFn == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "face detection unavailable, cannot redact frame"})
+		return
+	}
+	if mode != models.RedactionOff || annotated {
+		if data, err = h.renderFrame(c.Request.Context(), frameKey, data, mode, style, annotated, highlightTrack); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	c.Data(http.StatusOK, "image/jpeg", data)
 }
 
-// Snapshot proxies the face snapshot image from MinIO.
+// Snapshot proxies the face snapshot image from MinIO, obscured entirely when
+// the redaction mode (see Frame) covers the event's face.
 func (h *EventHandler) Snapshot(c *gin.Context) {
 	eventID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -283,11 +394,177 @@ func (h *EventHandler) Snapshot(c *gin.Context) {
 		return
 	}
 
+	mode, style, ok := h.redaction(c, ev.StreamID)
+	if !ok {
+		return
+	}
+
 	data, err := h.minio.GetObject(c.Request.Context(), ev.SnapshotKey)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "snapshot not found"})
 		return
 	}
 
+	if mode == models.RedactionAll || (mode == models.RedactionUnknown && ev.MatchedPersonID == nil) {
+		if data, err = redact.WholeJPEG(data, style); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	c.Data(http.StatusOK, "image/jpeg", data)
 }
+
+// redaction returns how a stream's images are redacted for this request: the
+// strictest of the stream's mode, the API key's and the redact query param, in
+// the stream's style. On a bad param it responds with 400 and returns false.
+func (h *EventHandler) redaction(c *gin.Context, streamID uuid.UUID) (models.RedactionMode, models.RedactionStyle, bool) {
+	requested := models.RedactionMode(c.DefaultQuery("redact", string(models.RedactionOff)))
+	if !requested.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "redact must be off, unknown or all"})
+		return "", "", false
+	}
+
+	st, err := h.db.GetStream(c.Request.Context(), streamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", "", false
+	}
+	var settings *models.StreamSettings
+	if st != nil {
+		// Stored configs were validated on write
+		settings, _ = models.ParseStreamSettings(st.Config)
+	}
+	mode, style := mergeRedaction(h.Redaction, h.RedactionStyle, settings, auth.Redaction(c), requested)
+	return mode, style, true
+}
+
+// mergeRedaction returns the redaction of a request: the stream's mode and
+// style (the global ones unless overridden), made as strict as the caller's
+// API key and the requested mode.
+func mergeRedaction(mode models.RedactionMode, style models.RedactionStyle, s *models.StreamSettings, key, requested models.RedactionMode) (models.RedactionMode, models.RedactionStyle) {
+	if s != nil {
+		if s.Redaction != nil {
+			mode = *s.Redaction
+		}
+		if s.RedactionStyle != nil {
+			style = *s.RedactionStyle
+		}
+	}
+	return mode.Stricter(key).Stricter(requested), style
+}
+
+// renderFrame redacts and annotates a stored frame with the faces of its
+// events, detecting the faces to obscure anew.
+func (h *EventHandler) renderFrame(ctx context.Context, frameKey string, data []byte, mode models.RedactionMode, style models.RedactionStyle, annotated bool, highlightTrack string) ([]byte, error) {
+	faces, err := h.db.ListFrameFaces(ctx, frameKey)
+	if err != nil {
+		return nil, err
+	}
+	var boxes [][4]float32
+	if mode != models.RedactionOff {
+		if boxes, err = h.DetectFn(data); err != nil {
+			return nil, fmt.Errorf("detect faces: %w", err)
+		}
+	}
+	return renderJPEG(data, faces, boxes, mode, style, annotated, highlightTrack)
+}
+
+// renderJPEG decodes a frame, obscures boxes unless mode is off, optionally
+// annotates faces, and re-encodes it. In unknown mode the boxes of faces with
+// a matched person stay visible; in all mode no names are drawn.
+func renderJPEG(data []byte, faces []storage.FrameFace, boxes [][4]float32, mode models.RedactionMode, style models.RedactionStyle, annotated bool, highlightTrack string) ([]byte, error) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode jpeg: %w", err)
+	}
+
+	if mode != models.RedactionOff {
+		if mode == models.RedactionUnknown {
+			boxes = slices.DeleteFunc(boxes, func(b [4]float32) bool {
+				return slices.ContainsFunc(faces, func(f storage.FrameFace) bool {
+					return f.PersonID != nil && boxIoU(b, f.BBox) >= 0.5
+				})
+			})
+		}
+		img = redact.Faces(img, boxes, style)
+	}
+
+	if annotated {
+		marks := make([]annotate.Face, 0, len(faces))
+		for _, f := range faces {
+			marks = append(marks, frameFaceMark(f, f.TrackID == highlightTrack, mode != models.RedactionAll))
+		}
+		img = annotate.Faces(img, marks)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// frameFaceMark labels a frame's face with its match (if names are shown), its
+// track ID without the stream prefix and its age and gender.
+func frameFaceMark(f storage.FrameFace, highlight, names bool) annotate.Face {
+	m := annotate.Face{BBox: f.BBox, Known: f.PersonID != nil, Highlight: highlight}
+	for i := 0; i+1 < len(f.Landmarks); i += 2 {
+		m.Landmarks = append(m.Landmarks, [2]float32{f.Landmarks[i], f.Landmarks[i+1]})
+	}
+	switch {
+	case !names:
+	case f.PersonID != nil:
+		m.Labels = append(m.Labels, fmt.Sprintf("%s %.2f", f.PersonName, f.MatchScore))
+	default:
+		m.Labels = append(m.Labels, "unknown")
+	}
+	track := f.TrackID
+	if _, after, ok := strings.Cut(track, "_"); ok {
+		track = after
+	}
+	m.Labels = append(m.Labels, "track "+track)
+	if f.Gender != "" || f.Age > 0 {
+		m.Labels = append(m.Labels, strings.TrimSpace(fmt.Sprintf("%s %d", f.Gender, f.Age)))
+	}
+	return m
+}
+
+// boxIoU is the intersection over union of two boxes (x1, y1, x2, y2).
+func boxIoU(a, b [4]float32) float32 {
+	w := min(a[2], b[2]) - max(a[0], b[0])
+	h := min(a[3], b[3]) - max(a[1], b[1])
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	inter := w * h
+	return inter / ((a[2]-a[0])*(a[3]-a[1]) + (b[2]-b[0])*(b[3]-b[1]) - inter)
+}
+
+// AttributesToResponse converts an event's face attribute labels for the API.
+func AttributesToResponse(attrs models.FaceAttributes) map[string]dto.FaceAttribute {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make(map[string]dto.FaceAttribute, len(attrs))
+	for name, a := range attrs {
+		out[name] = dto.FaceAttribute(a)
+	}
+	return out
+}
+
+// queryDegrees reads an optional non-negative angle query param. On a bad value
+// it responds with 400 and returns false.
+func queryDegrees(c *gin.Context, param string) (*float32, bool) {
+	s := c.Query(param)
+	if s == "" {
+		return nil, true
+	}
+	v, err := strconv.ParseFloat(s, 32)
+	if err != nil || v < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be a non-negative number of degrees"})
+		return nil, false
+	}
+	deg := float32(v)
+	return &deg, true
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+func TestMergeRedaction(t *testing.T) {
+	mode := func(m models.RedactionMode) *models.RedactionMode { return &m }
+	style := func(s models.RedactionStyle) *models.RedactionStyle { return &s }
+
+	tests := []struct {
+		name      string
+		global    models.RedactionMode
+		settings  *models.StreamSettings
+		key       models.RedactionMode
+		requested models.RedactionMode
+		want      models.RedactionMode
+		wantStyle models.RedactionStyle
+	}{
+		{name: "all off", global: models.RedactionOff, key: models.RedactionOff, requested: models.RedactionOff,
+			want: models.RedactionOff, wantStyle: models.RedactionPixelate},
+		{name: "global mode", global: models.RedactionUnknown, key: models.RedactionOff, requested: models.RedactionOff,
+			want: models.RedactionUnknown, wantStyle: models.RedactionPixelate},
+		{name: "stream overrides global", global: models.RedactionAll,
+			settings: &models.StreamSettings{Redaction: mode(models.RedactionOff), RedactionStyle: style(models.RedactionBlur)},
+			key:      models.RedactionOff, requested: models.RedactionOff,
+			want: models.RedactionOff, wantStyle: models.RedactionBlur},
+		{name: "stream without redaction keeps global", global: models.RedactionUnknown, settings: &models.StreamSettings{},
+			key: models.RedactionOff, requested: models.RedactionOff,
+			want: models.RedactionUnknown, wantStyle: models.RedactionPixelate},
+		{name: "api key is stricter", global: models.RedactionOff,
+			settings: &models.StreamSettings{Redaction: mode(models.RedactionUnknown)},
+			key:      models.RedactionAll, requested: models.RedactionOff,
+			want: models.RedactionAll, wantStyle: models.RedactionPixelate},
+		{name: "request cannot loosen the stream", global: models.RedactionOff,
+			settings: &models.StreamSettings{Redaction: mode(models.RedactionAll)},
+			key:      models.RedactionOff, requested: models.RedactionUnknown,
+			want: models.RedactionAll, wantStyle: models.RedactionPixelate},
+		{name: "request cannot loosen the api key", global: models.RedactionOff, key: models.RedactionUnknown,
+			requested: models.RedactionOff, want: models.RedactionUnknown, wantStyle: models.RedactionPixelate},
+		{name: "request is stricter", global: models.RedactionUnknown, key: models.RedactionOff,
+			requested: models.RedactionAll, want: models.RedactionAll, wantStyle: models.RedactionPixelate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotStyle := mergeRedaction(tt.global, models.RedactionPixelate, tt.settings, tt.key, tt.requested)
+			if got != tt.want || gotStyle != tt.wantStyle {
+				t.Errorf("mergeRedaction = %q, %q, want %q, %q", got, gotStyle, tt.want, tt.wantStyle)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/models"
+	"github.com/your-org/fd/internal/observability"
+	"github.com/your-org/fd/internal/storage"
+)
+
+const (
+	// liveBoundary separates the JPEG parts of a live preview.
+	liveBoundary = "fdframe"
+	// liveWriteTimeout bounds writing one frame to a slow viewer.
+	liveWriteTimeout = 10 * time.Second
+)
+
+// Live serves an MJPEG (multipart/x-mixed-replace) preview of a stream: each
+// frame a worker processes, with its tracked faces drawn on it (annotate=false
+// turns that off) and redacted like Frame. Query param fps lowers the frame
+// rate below the server.live_max_fps cap. Frames in between are skipped.
+func (h *EventHandler) Live(c *gin.Context) {
+	streamID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stream id"})
+		return
+	}
+
+	st, err := h.db.GetStream(c.Request.Context(), streamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if st == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "stream not found"})
+		return
+	}
+
+	mode, style, ok := h.redaction(c, streamID)
+	if !ok {
+		return
+	}
+	annotateStr := c.DefaultQuery("annotate", "true")
+	annotated := annotateStr != "false" && annotateStr != "0"
+
+	fps := h.LiveMaxFPS
+	if s := c.Query("fps"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "fps must be a positive number"})
+			return
+		}
+		fps = min(v, fps)
+	}
+	interval := time.Duration(float64(time.Second) / fps)
+
+	observability.LiveViewers.Inc()
+	defer observability.LiveViewers.Dec()
+	defer h.LiveFeed.Watch(streamID)()
+
+	c.Header("Content-Type", "multipart/x-mixed-replace; boundary="+liveBoundary)
+	c.Header("Cache-Control", "no-cache, no-store")
+	c.Status(http.StatusOK)
+	rc := http.NewResponseController(c.Writer)
+	ctx := c.Request.Context()
+
+	names := make(map[uuid.UUID]string) // person names, looked up once per viewer
+	var last time.Time
+	for {
+		frame, changed := h.LiveFeed.Latest(streamID)
+		if frame == nil || !frame.Timestamp.After(last) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+			continue
+		}
+		last = frame.Timestamp
+		next := time.Now().Add(interval)
+
+		data, err := h.liveFrame(ctx, frame, names, mode, style, annotated)
+		if err != nil {
+			// E.g. purged by frame_retention meanwhile: wait for the next one
+			slog.Debug("render live frame", "error", err, "frame", frame.FrameRef)
+		} else {
+			// The server's write timeout would end the feed otherwise
+			_ = rc.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+			if _, err := fmt.Fprintf(c.Writer, "--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n", liveBoundary, len(data)); err != nil {
+				return
+			}
+			if _, err := c.Writer.Write(append(data, '\r', '\n')); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+	}
+}
+
+// liveFrame loads a processed frame and draws its tracked faces, filling in
+// the names of matched persons from names or the database.
+func (h *EventHandler) liveFrame(ctx context.Context, frame *models.LiveFrame, names map[uuid.UUID]string, mode models.RedactionMode, style models.RedactionStyle, annotated bool) ([]byte, error) {
+	data, err := h.minio.GetObject(ctx, frame.FrameRef)
+	if err != nil {
+		return nil, err
+	}
+	if mode == models.RedactionOff && !annotated {
+		return data, nil
+	}
+
+	faces := make([]storage.FrameFace, 0, len(frame.Tracks))
+	for _, t := range frame.Tracks {
+		f := storage.FrameFace{
+			TrackID:    t.TrackID,
+			BBox:       t.BBox,
+			PersonID:   t.PersonID,
+			MatchScore: t.MatchScore,
+			Gender:     t.Gender,
+			Age:        t.Age,
+		}
+		for _, p := range t.Landmarks {
+			f.Landmarks = append(f.Landmarks, p[0], p[1])
+		}
+		if t.PersonID != nil {
+			name, ok := names[*t.PersonID]
+			if !ok {
+				p, err := h.db.GetPerson(ctx, *t.PersonID)
+				if err != nil {
+					return nil, err
+				}
+				if p != nil {
+					name = p.Name
+				}
+				names[*t.PersonID] = name
+			}
+			f.PersonName = name
+		}
+		faces = append(faces, f)
+	}
+	return renderJPEG(data, faces, frame.Faces, mode, style, annotated, "")
+}
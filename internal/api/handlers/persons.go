@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/your-org/fd/internal/models"
 	"github.com/your-org/fd/internal/storage"
 	"github.com/your-org/fd/pkg/dto"
 )
@@ -16,7 +17,19 @@ type PersonHandler struct {
 	minio *storage.MinIOStore
 	// embedFn extracts a face embedding from image bytes.
 	// Set this after vision pipeline is initialized.
-	EmbedFn func(imageData []byte) ([]float32, float32, error)
+	EmbedFn func(imageData []byte) ([]float32, models.FaceQuality, error)
+	// EnrollMinQuality is the minimum quality score for AddFace photos (<0 = accept all).
+	EnrollMinQuality float32
+	// ModelVersion is the version of the embedder behind EmbedFn.
+	ModelVersion string
+	// Reembed re-embeds enrollment faces of other model versions; nil without a vision pipeline.
+	Reembed ReembedJob
+}
+
+// ReembedJob is the background job that re-embeds enrollment faces with the current model.
+type ReembedJob interface {
+	Start() bool
+	Status() models.ReembedStatus
 }
 
 func NewPersonHandler(db *storage.PostgresStore, minio *storage.MinIOStore) *PersonHandler {
@@ -120,6 +133,8 @@ func (h *PersonHandler) Get(c *gin.Context) {
 }
 
 // AddFace accepts a multipart image upload, extracts embedding, and stores it.
+// Photos below the enrollment quality minimum are rejected with the reasons,
+// unless the form field force=true is set.
 func (h *PersonHandler) AddFace(c *gin.Context) {
 	personID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -162,6 +177,14 @@ func (h *PersonHandler) AddFace(c *gin.Context) {
 		return
 	}
 
+	if quality.Score < h.EnrollMinQuality && c.PostForm("force") != "true" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "face quality too low for enrollment",
+			"quality": quality,
+		})
+		return
+	}
+
 	// Store source image in MinIO
 	sourceKey := "faces/" + personID.String() + "/" + uuid.New().String() + "_" + header.Filename
 	if err := h.minio.PutObject(c.Request.Context(), sourceKey, imageData, header.Header.Get("Content-Type")); err != nil {
@@ -169,18 +192,20 @@ func (h *PersonHandler) AddFace(c *gin.Context) {
 		return
 	}
 
-	fe, err := h.db.AddFaceEmbedding(c.Request.Context(), personID, embedding, quality, sourceKey)
+	fe, err := h.db.AddFaceEmbedding(c.Request.Context(), personID, embedding, h.ModelVersion, quality.Score, sourceKey)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, dto.FaceEmbeddingResponse{
-		ID:        fe.ID,
-		PersonID:  fe.PersonID,
-		Quality:   fe.Quality,
-		SourceKey: fe.SourceKey,
-		CreatedAt: fe.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		ID:             fe.ID,
+		PersonID:       fe.PersonID,
+		Quality:        fe.Quality,
+		QualityReasons: quality.Reasons,
+		ModelVersion:   fe.ModelVersion,
+		SourceKey:      fe.SourceKey,
+		CreatedAt:      fe.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	})
 }
 
@@ -220,11 +245,12 @@ func (h *PersonHandler) ListFaces(c *gin.Context) {
 	resp := make([]dto.FaceEmbeddingResponse, 0, len(faces))
 	for _, f := range faces {
 		resp = append(resp, dto.FaceEmbeddingResponse{
-			ID:        f.ID,
-			PersonID:  f.PersonID,
-			Quality:   f.Quality,
-			SourceKey: f.SourceKey,
-			CreatedAt: f.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			ID:           f.ID,
+			PersonID:     f.PersonID,
+			Quality:      f.Quality,
+			ModelVersion: f.ModelVersion,
+			SourceKey:    f.SourceKey,
+			CreatedAt:    f.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		})
 	}
 
@@ -267,7 +293,7 @@ func (h *PersonHandler) Search(c *gin.Context) {
 	threshold := 0.4
 	limit := 5
 
-	matches, err := h.db.SearchFaces(c.Request.Context(), embedding, collectionID, threshold, limit)
+	matches, err := h.db.SearchFaces(c.Request.Context(), embedding, h.ModelVersion, collectionID, threshold, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -284,3 +310,26 @@ func (h *PersonHandler) Search(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"results": results, "total": len(results)})
 }
+
+// StartReembed starts re-embedding the stored enrollment images of faces whose
+// embedding was produced by another model version. Returns 409 if a run is in progress.
+func (h *PersonHandler) StartReembed(c *gin.Context) {
+	if h.Reembed == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "vision pipeline not initialized"})
+		return
+	}
+	if !h.Reembed.Start() {
+		c.JSON(http.StatusConflict, gin.H{"error": "re-embedding already running", "status": h.Reembed.Status()})
+		return
+	}
+	c.JSON(http.StatusAccepted, h.Reembed.Status())
+}
+
+// ReembedStatus returns the progress of the current or last re-embedding run.
+func (h *PersonHandler) ReembedStatus(c *gin.Context) {
+	if h.Reembed == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "vision pipeline not initialized"})
+		return
+	}
+	c.JSON(http.StatusOK, h.Reembed.Status())
+}
@@ -29,6 +29,17 @@ func (h *StreamHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// identify mode only reports faces matched in the stream's collection
+	if models.StreamMode(req.Mode) == models.StreamModeIdentify && req.CollectionID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "identify mode requires collection_id"})
+		return
+	}
+
+	if _, err := models.ParseStreamSettings(req.Config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	fps := req.FPS
 	if fps <= 0 {
 		fps = 5
@@ -126,6 +137,9 @@ func (h *StreamHandler) Start(c *gin.Context) {
 	if st.CollectionID != nil {
 		cmd["collection_id"] = st.CollectionID.String()
 	}
+	if len(st.Config) > 0 {
+		cmd["config"] = st.Config
+	}
 
 	cmdData, _ := json.Marshal(cmd)
 	if err := h.producer.PublishControl(cmdData); err != nil {
@@ -200,6 +214,115 @@ func (h *StreamHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
 
+// GetRegions returns the stream's ROI and exclusion polygons.
+func (h *StreamHandler) GetRegions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stream id"})
+		return
+	}
+
+	st, err := h.db.GetStream(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if st == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "stream not found"})
+		return
+	}
+
+	settings, err := models.ParseStreamSettings(st.Config)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, regionsToResponse(settings))
+}
+
+// PutRegions replaces the stream's ROI and exclusion polygons. The rest of the
+// stream config is kept. A running stream picks the change up without a restart.
+func (h *StreamHandler) PutRegions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stream id"})
+		return
+	}
+
+	var req dto.StreamRegions
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	st, err := h.db.GetStream(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if st == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "stream not found"})
+		return
+	}
+
+	// Patch the two keys into the stored config as raw JSON so the other
+	// overrides keep exactly the form they were written in.
+	config := make(map[string]json.RawMessage)
+	if len(st.Config) > 0 {
+		if err := json.Unmarshal(st.Config, &config); err != nil || config == nil {
+			config = make(map[string]json.RawMessage)
+		}
+	}
+	setPolygons(config, "roi", req.ROI)
+	setPolygons(config, "exclude", req.Exclude)
+	raw, _ := json.Marshal(config)
+
+	settings, err := models.ParseStreamSettings(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.UpdateStreamConfig(c.Request.Context(), id, raw); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if st.Status == models.StreamStatusRunning || st.Status == models.StreamStatusStarting {
+		cmd := map[string]interface{}{
+			"action":    "configure",
+			"stream_id": id.String(),
+			"config":    json.RawMessage(raw),
+		}
+		cmdData, _ := json.Marshal(cmd)
+		_ = h.producer.PublishControl(cmdData)
+	}
+
+	c.JSON(http.StatusOK, regionsToResponse(settings))
+}
+
+func setPolygons(config map[string]json.RawMessage, key string, polys [][][2]float64) {
+	if len(polys) == 0 {
+		delete(config, key)
+		return
+	}
+	config[key], _ = json.Marshal(polys)
+}
+
+func regionsToResponse(s *models.StreamSettings) dto.StreamRegions {
+	resp := dto.StreamRegions{ROI: [][][2]float64{}, Exclude: [][][2]float64{}}
+	if s == nil {
+		return resp
+	}
+	for _, p := range s.ROI {
+		resp.ROI = append(resp.ROI, p)
+	}
+	for _, p := range s.Exclude {
+		resp.Exclude = append(resp.Exclude, p)
+	}
+	return resp
+}
+
 func streamToResponse(st *models.Stream) dto.StreamResponse {
 	return dto.StreamResponse{
 		ID:           st.ID,
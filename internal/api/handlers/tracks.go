@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/models"
+	"github.com/your-org/fd/internal/storage"
+	"github.com/your-org/fd/pkg/dto"
+)
+
+type TrackHandler struct {
+	db *storage.PostgresStore
+}
+
+func NewTrackHandler(db *storage.PostgresStore) *TrackHandler {
+	return &TrackHandler{db: db}
+}
+
+// List returns the tracks of a stream with their dwell time.
+// Optional query params: from, to (RFC3339), person_id, status (active|ended), limit, offset.
+func (h *TrackHandler) List(c *gin.Context) {
+	streamID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stream id"})
+		return
+	}
+
+	var from, to *time.Time
+	if fromStr := c.Query("from"); fromStr != "" {
+		if t, err := time.Parse(time.RFC3339, fromStr); err == nil {
+			from = &t
+		}
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		if t, err := time.Parse(time.RFC3339, toStr); err == nil {
+			to = &t
+		}
+	}
+
+	var personID *uuid.UUID
+	if pidStr := c.Query("person_id"); pidStr != "" {
+		if id, err := uuid.Parse(pidStr); err == nil {
+			personID = &id
+		}
+	}
+
+	var status *models.TrackStatus
+	if s := models.TrackStatus(c.Query("status")); s != "" {
+		if s != models.TrackStatusActive && s != models.TrackStatusEnded {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active or ended"})
+			return
+		}
+		status = &s
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	tracks, total, err := h.db.QueryTracks(c.Request.Context(), streamID, from, to, personID, status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]dto.TrackResponse, 0, len(tracks))
+	for i := range tracks {
+		resp = append(resp, trackToResponse(&tracks[i]))
+	}
+
+	c.JSON(http.StatusOK, dto.TrackListResponse{Tracks: resp, Total: total})
+}
+
+func trackToResponse(t *models.Track) dto.TrackResponse {
+	return dto.TrackResponse{
+		ID:               t.ID,
+		StreamID:         t.StreamID,
+		TrackID:          t.TrackID,
+		Status:           string(t.Status),
+		FirstSeen:        t.FirstSeen.Format(time.RFC3339),
+		LastSeen:         t.LastSeen.Format(time.RFC3339),
+		DwellSeconds:     t.DwellSeconds,
+		FrameCount:       t.FrameCount,
+		PersonID:         t.PersonID,
+		MatchScore:       t.MatchScore,
+		Gender:           t.Gender,
+		GenderConfidence: t.GenderConfidence,
+		Age:              t.Age,
+		AgeRange:         t.AgeRange,
+		AttributeSamples: t.AttributeSamples,
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/models"
+	"github.com/your-org/fd/internal/storage"
+	"github.com/your-org/fd/pkg/dto"
+)
+
+type VisitorHandler struct {
+	db *storage.PostgresStore
+}
+
+func NewVisitorHandler(db *storage.PostgresStore) *VisitorHandler {
+	return &VisitorHandler{db: db}
+}
+
+// List returns anonymous visitors, most recently seen first.
+// Optional query params: min_tracks, promoted (true|false), limit, offset.
+func (h *VisitorHandler) List(c *gin.Context) {
+	minTracks, _ := strconv.Atoi(c.DefaultQuery("min_tracks", "1"))
+
+	var promoted *bool
+	if s := c.Query("promoted"); s != "" {
+		b := s == "true" || s == "1"
+		promoted = &b
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	visitors, total, err := h.db.QueryVisitors(c.Request.Context(), minTracks, promoted, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]dto.VisitorResponse, 0, len(visitors))
+	for i := range visitors {
+		resp = append(resp, visitorToResponse(&visitors[i]))
+	}
+	c.JSON(http.StatusOK, dto.VisitorListResponse{Visitors: resp, Total: total})
+}
+
+func (h *VisitorHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid visitor id"})
+		return
+	}
+
+	v, err := h.db.GetVisitor(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if v == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "visitor not found"})
+		return
+	}
+	c.JSON(http.StatusOK, visitorToResponse(v))
+}
+
+// Promote turns a visitor into a known person, enrolling every embedding of
+// its events as a face of that person.
+func (h *VisitorHandler) Promote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid visitor id"})
+		return
+	}
+
+	var req dto.PromoteVisitorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	col, err := h.db.GetCollection(c.Request.Context(), req.CollectionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if col == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "collection not found"})
+		return
+	}
+
+	person, faces, err := h.db.PromoteVisitor(c.Request.Context(), id, req.CollectionID, req.Name, req.Metadata)
+	if errors.Is(err, storage.ErrVisitorPromoted) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, storage.ErrVisitorRedacted) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if person == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "visitor not found"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.PersonResponse{
+		ID:           person.ID,
+		CollectionID: person.CollectionID,
+		Name:         person.Name,
+		Metadata:     person.Metadata,
+		FaceCount:    faces,
+		CreatedAt:    person.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	})
+}
+
+func visitorToResponse(v *models.Visitor) dto.VisitorResponse {
+	urls := make([]string, 0, len(v.SnapshotIDs))
+	for _, id := range v.SnapshotIDs {
+		urls = append(urls, "/v1/events/"+id.String()+"/snapshot")
+	}
+	return dto.VisitorResponse{
+		ID:           v.ID,
+		ModelVersion: v.ModelVersion,
+		TrackCount:   v.TrackCount,
+		EventCount:   v.EventCount,
+		FirstSeen:    v.FirstSeen.Format(time.RFC3339),
+		LastSeen:     v.LastSeen.Format(time.RFC3339),
+		PersonID:     v.PersonID,
+		SnapshotURLs: urls,
+		CreatedAt:    v.CreatedAt.Format(time.RFC3339),
+	}
+}
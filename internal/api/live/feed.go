@@ -0,0 +1,102 @@
+// Package live keeps the latest processed frame of each stream for live
+// previews.
+package live
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+// feedIdle is how long a stream without viewers is kept after its last frame,
+// so stopped and deleted streams are eventually forgotten.
+const feedIdle = time.Minute
+
+// Feed holds the latest frame per stream and wakes viewers when it changes.
+type Feed struct {
+	mu      sync.Mutex
+	streams map[uuid.UUID]*latest
+	swept   time.Time // last removal of idle streams
+	now     func() time.Time
+}
+
+type latest struct {
+	frame   *models.LiveFrame
+	changed chan struct{} // closed and replaced on each new frame
+	viewers int
+	updated time.Time // when the entry was created or last got a frame
+}
+
+func NewFeed() *Feed {
+	return &Feed{streams: make(map[uuid.UUID]*latest), swept: time.Now(), now: time.Now}
+}
+
+// Watch registers a viewer of a stream, which keeps the stream from being
+// forgotten. The returned func unregisters it.
+func (f *Feed) Watch(streamID uuid.UUID) func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	l := f.get(streamID)
+	l.viewers++
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		l.viewers--
+	}
+}
+
+// Publish makes frame the latest of its stream, unless a newer one is already
+// there (workers may finish frames out of order).
+func (f *Feed) Publish(frame *models.LiveFrame) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sweep()
+	l := f.get(frame.StreamID)
+	if l.frame != nil && frame.Timestamp.Before(l.frame.Timestamp) {
+		return
+	}
+	l.frame = frame
+	l.updated = f.now()
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Latest returns the latest frame of a stream (nil if none yet) and a channel
+// that is closed when a newer one arrives. Viewers waiting on the channel must
+// Watch the stream.
+func (f *Feed) Latest(streamID uuid.UUID) (*models.LiveFrame, <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	l := f.get(streamID)
+	return l.frame, l.changed
+}
+
+func (f *Feed) get(streamID uuid.UUID) *latest {
+	l, ok := f.streams[streamID]
+	if !ok {
+		l = &latest{changed: make(chan struct{}), updated: f.now()}
+		f.streams[streamID] = l
+	}
+	return l
+}
+
+// sweep forgets the streams without viewers that had no frame for feedIdle,
+// at most once per feedIdle.
+func (f *Feed) sweep() {
+	now := f.now()
+	if now.Sub(f.swept) < feedIdle {
+		return
+	}
+	f.swept = now
+	for id, l := range f.streams {
+		if l.viewers == 0 && now.Sub(l.updated) >= feedIdle {
+			delete(f.streams, id)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package live
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+func TestFeedPublish(t *testing.T) {
+	stream := uuid.New()
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	frame := func(offset time.Duration, ref string) *models.LiveFrame {
+		return &models.LiveFrame{StreamID: stream, Timestamp: t0.Add(offset), FrameRef: ref}
+	}
+
+	tests := []struct {
+		name      string
+		published []*models.LiveFrame
+		want      string // FrameRef of the latest frame, "" = none
+	}{
+		{name: "nothing published"},
+		{name: "one frame", published: []*models.LiveFrame{frame(0, "a")}, want: "a"},
+		{name: "in order", published: []*models.LiveFrame{frame(0, "a"), frame(time.Second, "b")}, want: "b"},
+		{name: "older frame arrives late", published: []*models.LiveFrame{frame(time.Second, "b"), frame(0, "a")}, want: "b"},
+		{name: "same timestamp replaces", published: []*models.LiveFrame{frame(0, "a"), frame(0, "b")}, want: "b"},
+		{name: "newer after a late one", published: []*models.LiveFrame{
+			frame(time.Second, "b"), frame(0, "a"), frame(2*time.Second, "c"),
+		}, want: "c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFeed()
+			for _, fr := range tt.published {
+				f.Publish(fr)
+			}
+			got, _ := f.Latest(stream)
+			switch {
+			case got == nil && tt.want != "":
+				t.Fatalf("Latest = nil, want %q", tt.want)
+			case got != nil && got.FrameRef != tt.want:
+				t.Fatalf("Latest = %q, want %q", got.FrameRef, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeedWakesViewers(t *testing.T) {
+	stream := uuid.New()
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	f := NewFeed()
+
+	_, changed := f.Latest(stream)
+	f.Publish(&models.LiveFrame{StreamID: stream, Timestamp: t0})
+	select {
+	case <-changed:
+	default:
+		t.Fatal("viewer not woken by a new frame")
+	}
+
+	_, changed = f.Latest(stream)
+	f.Publish(&models.LiveFrame{StreamID: stream, Timestamp: t0.Add(-time.Second)})
+	select {
+	case <-changed:
+		t.Fatal("viewer woken by an older frame")
+	default:
+	}
+
+	f.Publish(&models.LiveFrame{StreamID: uuid.New(), Timestamp: t0.Add(time.Second)})
+	select {
+	case <-changed:
+		t.Fatal("viewer woken by a frame of another stream")
+	default:
+	}
+}
+
+func TestFeedForgetsIdleStreams(t *testing.T) {
+	idle, watched, active := uuid.New(), uuid.New(), uuid.New()
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := t0
+	f := NewFeed()
+	f.now = func() time.Time { return clock }
+	f.swept = t0
+
+	f.Publish(&models.LiveFrame{StreamID: idle, Timestamp: t0})
+	f.Publish(&models.LiveFrame{StreamID: watched, Timestamp: t0})
+	unwatch := f.Watch(watched)
+
+	clock = t0.Add(feedIdle)
+	f.Publish(&models.LiveFrame{StreamID: active, Timestamp: clock})
+	if _, ok := f.streams[idle]; ok {
+		t.Error("idle stream kept")
+	}
+	if _, ok := f.streams[watched]; !ok {
+		t.Error("watched stream forgotten")
+	}
+
+	unwatch()
+	clock = t0.Add(feedIdle + feedIdle/2)
+	f.Publish(&models.LiveFrame{StreamID: active, Timestamp: clock})
+	if _, ok := f.streams[watched]; !ok {
+		t.Error("stream forgotten before the next sweep")
+	}
+
+	clock = t0.Add(2 * feedIdle)
+	f.Publish(&models.LiveFrame{StreamID: active, Timestamp: clock})
+	if _, ok := f.streams[watched]; ok {
+		t.Error("unwatched idle stream kept")
+	}
+	if got, _ := f.Latest(active); got == nil || !got.Timestamp.Equal(clock) {
+		t.Errorf("Latest(active) = %v, want the frame at %v", got, clock)
+	}
+}
@@ -6,20 +6,44 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/your-org/fd/internal/api/handlers"
+	"github.com/your-org/fd/internal/api/live"
 	"github.com/your-org/fd/internal/api/ws"
 	"github.com/your-org/fd/internal/auth"
+	"github.com/your-org/fd/internal/clustering"
+	"github.com/your-org/fd/internal/config"
+	"github.com/your-org/fd/internal/models"
 	"github.com/your-org/fd/internal/queue"
 	"github.com/your-org/fd/internal/storage"
 )
 
 type RouterConfig struct {
-	APIKey   string
+	APIKey string
+	// APIKeys are further API keys, e.g. restricted to redacted frames.
+	APIKeys  []auth.Key
 	DB       *storage.PostgresStore
 	MinIO    *storage.MinIOStore
 	Producer *queue.Producer
 	Hub      *ws.Hub
+	// Live holds the latest processed frame of each stream for live previews.
+	Live *live.Feed
+	// LiveMaxFPS caps the frame rate of each live preview.
+	LiveMaxFPS float64
 	// EmbedFn extracts a face embedding from image bytes (from vision pipeline).
-	EmbedFn func(imageData []byte) ([]float32, float32, error)
+	EmbedFn func(imageData []byte) ([]float32, models.FaceQuality, error)
+	// EnrollMinQuality is the minimum face quality accepted by AddFace.
+	EnrollMinQuality float32
+	// ModelVersion is the version of the embedder behind EmbedFn.
+	ModelVersion string
+	// Reembed is the enrollment re-embedding job (nil without a vision pipeline).
+	Reembed handlers.ReembedJob
+	// DetectFn finds the face boxes of a JPEG frame (from vision pipeline).
+	DetectFn func(imageData []byte) ([][4]float32, error)
+	// Redaction and RedactionStyle are the global redaction settings, which
+	// streams may override.
+	Redaction      models.RedactionMode
+	RedactionStyle models.RedactionStyle
+	// Clustering holds the visitor clustering settings, which footfall counts with.
+	Clustering config.ClusteringConfig
 }
 
 func NewRouter(cfg RouterConfig) *gin.Engine {
@@ -38,7 +62,7 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 
 	// API v1 (with auth)
 	v1 := r.Group("/v1")
-	v1.Use(auth.APIKeyMiddleware(cfg.APIKey))
+	v1.Use(auth.APIKeyMiddleware(cfg.APIKey, cfg.APIKeys...))
 
 	// WebSocket
 	v1.GET("/ws", cfg.Hub.HandleWS)
@@ -51,6 +75,9 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 	// Persons & Faces
 	personH := handlers.NewPersonHandler(cfg.DB, cfg.MinIO)
 	personH.EmbedFn = cfg.EmbedFn
+	personH.EnrollMinQuality = cfg.EnrollMinQuality
+	personH.ModelVersion = cfg.ModelVersion
+	personH.Reembed = cfg.Reembed
 	v1.POST("/persons", personH.Create)
 	v1.GET("/persons", personH.List)
 	v1.GET("/persons/:id", personH.Get)
@@ -58,6 +85,8 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 	v1.GET("/persons/:id/faces", personH.ListFaces)
 	v1.DELETE("/persons/:id/faces/:faceId", personH.DeleteFace)
 	v1.POST("/search", personH.Search)
+	v1.POST("/faces/reembed", personH.StartReembed)
+	v1.GET("/faces/reembed", personH.ReembedStatus)
 
 	// Streams
 	streamH := handlers.NewStreamHandler(cfg.DB, cfg.Producer)
@@ -66,16 +95,44 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 	v1.GET("/streams/:id", streamH.Get)
 	v1.POST("/streams/:id/start", streamH.Start)
 	v1.POST("/streams/:id/stop", streamH.Stop)
+	v1.GET("/streams/:id/regions", streamH.GetRegions)
+	v1.PUT("/streams/:id/regions", streamH.PutRegions)
 	v1.DELETE("/streams/:id", streamH.Delete)
 
 	// Events
 	eventH := handlers.NewEventHandler(cfg.DB, cfg.MinIO)
 	eventH.EmbedFn = cfg.EmbedFn
+	eventH.DetectFn = cfg.DetectFn
+	eventH.Redaction = cfg.Redaction
+	eventH.RedactionStyle = cfg.RedactionStyle
+	eventH.ModelVersion = cfg.ModelVersion
+	eventH.LiveFeed = cfg.Live
+	eventH.LiveMaxFPS = cfg.LiveMaxFPS
 	v1.GET("/streams/:id/events", eventH.List)
+	v1.GET("/streams/:id/frame", eventH.LatestFrame)
+	// Outside v1: the live preview also takes the key as a query param
+	r.GET("/v1/streams/:id/live", auth.QueryKeyMiddleware(cfg.APIKey, cfg.APIKeys...), eventH.Live)
 	v1.GET("/events/:id/snapshot", eventH.Snapshot)
 	v1.GET("/events/:id/frame", eventH.Frame)
 	v1.GET("/events/similar", eventH.SimilarByTrack)
 	v1.POST("/search/events", eventH.SearchEvents)
 
+	// Tracks
+	trackH := handlers.NewTrackHandler(cfg.DB)
+	v1.GET("/streams/:id/tracks", trackH.List)
+
+	// Visitors (anonymous identities of unknown faces)
+	visitorH := handlers.NewVisitorHandler(cfg.DB)
+	v1.GET("/visitors", visitorH.List)
+	v1.GET("/visitors/:id", visitorH.Get)
+	v1.POST("/visitors/:id/promote", visitorH.Promote)
+
+	// Analytics
+	analyticsH := handlers.NewAnalyticsHandler(cfg.DB)
+	analyticsH.Footfall = clustering.NewFootfall(cfg.DB, cfg.Clustering)
+	v1.GET("/streams/:id/analytics", analyticsH.Stream)
+	v1.GET("/analytics/visitors", analyticsH.Visitors)
+	v1.GET("/analytics/attributes", analyticsH.Attributes)
+
 	return r
 }
@@ -5,34 +5,90 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/your-org/fd/internal/models"
 )
 
 const headerName = "X-API-Key"
 
-// APIKeyMiddleware validates the API key from the X-API-Key header.
-// If apiKey is empty, authentication is disabled.
-func APIKeyMiddleware(apiKey string) gin.HandlerFunc {
+// queryParam carries the API key for clients that cannot set headers, e.g. a
+// browser showing the live preview in an <img> tag. Only QueryKeyMiddleware
+// reads it.
+const queryParam = "api_key"
+
+// redactionKey is the gin context key of the caller's redaction mode.
+const redactionKey = "auth.redaction"
+
+// Key is an additional API key whose callers only get frames and snapshots
+// redacted at least as strictly as Redaction.
+type Key struct {
+	Value     string
+	Redaction models.RedactionMode
+}
+
+// APIKeyMiddleware validates the API key from the X-API-Key header against
+// apiKey (full access) and keys. If both are empty, authentication is disabled.
+func APIKeyMiddleware(apiKey string, keys ...Key) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if apiKey == "" {
+		if apiKey == "" && len(keys) == 0 {
 			c.Next()
 			return
 		}
+		checkKey(c, c.GetHeader(headerName), apiKey, keys)
+	}
+}
 
-		provided := c.GetHeader(headerName)
-		if provided == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "missing API key",
+// QueryKeyMiddleware is APIKeyMiddleware for routes opened by clients that
+// cannot set headers: the key may also be given as the api_key query param.
+// Since such URLs end up in browser histories and logs, a key is always
+// required, even if authentication is disabled.
+func QueryKeyMiddleware(apiKey string, keys ...Key) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey == "" && len(keys) == 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "an API key is required, configure server.api_key",
 			})
 			return
 		}
+		provided := c.GetHeader(headerName)
+		if provided == "" {
+			provided = c.Query(queryParam)
+		}
+		checkKey(c, provided, apiKey, keys)
+	}
+}
 
-		if subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) != 1 {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"error": "invalid API key",
-			})
+// checkKey continues the request if provided is apiKey or one of keys, and
+// aborts it otherwise.
+func checkKey(c *gin.Context, provided, apiKey string, keys []Key) {
+	if provided == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "missing API key",
+		})
+		return
+	}
+
+	if apiKey != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) == 1 {
+		c.Next()
+		return
+	}
+	for _, k := range keys {
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(k.Value)) == 1 {
+			c.Set(redactionKey, k.Redaction)
+			c.Next()
 			return
 		}
+	}
 
-		c.Next()
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error": "invalid API key",
+	})
+}
+
+// Redaction returns the redaction mode the caller's API key requires.
+func Redaction(c *gin.Context) models.RedactionMode {
+	if m, ok := c.Get(redactionKey); ok {
+		return m.(models.RedactionMode)
 	}
+	return models.RedactionOff
 }
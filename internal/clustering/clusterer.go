@@ -0,0 +1,180 @@
+// Package clustering groups unknown faces into anonymous visitors and counts
+// unique visitors over time.
+package clustering
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/config"
+	"github.com/your-org/fd/internal/observability"
+	"github.com/your-org/fd/internal/storage"
+)
+
+// neighbourCandidates is the number of nearest events examined per track.
+const neighbourCandidates = 200
+
+// Clusterer runs incremental density-based clustering (DBSCAN over tracks) on
+// the embeddings of unknown events. Each unknown track is visited once,
+// represented by its best-quality embedding:
+//
+//   - a track whose earlier events already joined a visitor stays with it;
+//   - a track with neighbours in a visitor joins the closest one;
+//   - a core track (min_tracks within max_distance) also pulls its unassigned
+//     neighbours in, merges the visitors it touches (keeping the promoted or
+//     else the oldest one), or starts a new visitor. Visitors promoted to two
+//     different persons are never merged: the track joins the closest one;
+//   - any other track is noise until a later core track reaches it.
+//
+// Visitor IDs are stable: they only disappear when merged into another visitor.
+type Clusterer struct {
+	db  *storage.PostgresStore
+	cfg config.ClusteringConfig
+}
+
+func NewClusterer(db *storage.PostgresStore, cfg config.ClusteringConfig) *Clusterer {
+	return &Clusterer{db: db, cfg: cfg}
+}
+
+// Run clusters every cfg.Interval until ctx is cancelled.
+func (c *Clusterer) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := c.Pass(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("visitor clustering", "error", err, "tracks", n)
+		} else if n > 0 {
+			slog.Info("visitor clustering pass", "tracks", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Pass visits every unknown track with settled, unclustered events and
+// returns the number of tracks visited.
+func (c *Clusterer) Pass(ctx context.Context) (int, error) {
+	visited := 0
+	for {
+		seeds, err := c.db.ListClusterSeeds(ctx, time.Now().Add(-c.cfg.SettleDelay), c.cfg.BatchSize)
+		if err != nil {
+			return visited, err
+		}
+
+		touched := make(map[uuid.UUID]bool)
+		placed := make(map[string]bool) // tracks already pulled into a visitor by an earlier seed
+		for _, seed := range seeds {
+			if err := ctx.Err(); err != nil {
+				return visited, err
+			}
+			if placed[trackKey(seed.StreamID, seed.TrackID)] {
+				continue
+			}
+			result, err := c.visit(ctx, seed, touched, placed)
+			if err != nil {
+				return visited, fmt.Errorf("track %s: %w", seed.TrackID, err)
+			}
+			observability.VisitorTracksClustered.WithLabelValues(result).Inc()
+			visited++
+		}
+
+		ids := make([]uuid.UUID, 0, len(touched))
+		for id := range touched {
+			ids = append(ids, id)
+		}
+		if err := c.db.RefreshVisitors(ctx, ids); err != nil {
+			return visited, err
+		}
+
+		if len(seeds) < c.cfg.BatchSize {
+			return visited, nil
+		}
+	}
+}
+
+// visit clusters one track, recording the visitors it changed in touched and
+// the neighbours it assigned in placed. Returns "joined", "created" or "noise".
+func (c *Clusterer) visit(ctx context.Context, seed storage.ClusterSeed, touched map[uuid.UUID]bool, placed map[string]bool) (string, error) {
+	// Events that arrived after the track was clustered follow its visitor
+	if seed.VisitorID != nil {
+		touched[*seed.VisitorID] = true
+		return "joined", c.db.AssignTrackVisitor(ctx, seed.StreamID, seed.TrackID, seed.VisitorID)
+	}
+
+	neighbours, err := c.db.ClusterNeighbours(ctx, seed, c.cfg.MaxDistance, neighbourCandidates)
+	if err != nil {
+		return "", err
+	}
+	core := len(neighbours)+1 >= c.cfg.MinTracks
+
+	visitors, merge := neighbourVisitors(neighbours, core)
+
+	var target uuid.UUID
+	result := "joined"
+	switch {
+	case merge:
+		if target, err = c.db.MergeVisitors(ctx, visitors); err != nil {
+			return "", err
+		}
+	case len(visitors) > 0:
+		target = visitors[0]
+	case core:
+		if target, err = c.db.CreateVisitor(ctx, seed.ModelVersion); err != nil {
+			return "", err
+		}
+		result = "created"
+	default:
+		return "noise", c.db.AssignTrackVisitor(ctx, seed.StreamID, seed.TrackID, nil)
+	}
+	touched[target] = true
+
+	if err := c.db.AssignTrackVisitor(ctx, seed.StreamID, seed.TrackID, &target); err != nil {
+		return "", err
+	}
+	if core {
+		for _, n := range neighbours {
+			if n.VisitorID == nil {
+				if err := c.db.AssignTrackVisitor(ctx, n.StreamID, n.TrackID, &target); err != nil {
+					return "", err
+				}
+				placed[trackKey(n.StreamID, n.TrackID)] = true
+			}
+		}
+	}
+	return result, nil
+}
+
+// neighbourVisitors returns the visitors among a track's neighbours, closest
+// first, and whether a core track merges them. Two promoted visitors are two
+// different persons, so they are never merged.
+func neighbourVisitors(neighbours []storage.ClusterNeighbour, core bool) ([]uuid.UUID, bool) {
+	var visitors []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	promoted := 0
+	for _, n := range neighbours {
+		if n.VisitorID != nil && !seen[*n.VisitorID] {
+			seen[*n.VisitorID] = true
+			visitors = append(visitors, *n.VisitorID)
+			if n.Promoted {
+				promoted++
+			}
+		}
+	}
+	return visitors, core && len(visitors) > 1 && promoted <= 1
+}
+
+func trackKey(streamID uuid.UUID, trackID string) string {
+	return streamID.String() + "/" + trackID
+}
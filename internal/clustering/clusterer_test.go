@@ -0,0 +1,40 @@
+package clustering
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/storage"
+)
+
+func TestNeighbourVisitors(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	n := func(v *uuid.UUID, promoted bool) storage.ClusterNeighbour {
+		return storage.ClusterNeighbour{VisitorID: v, Promoted: promoted}
+	}
+
+	tests := []struct {
+		name       string
+		neighbours []storage.ClusterNeighbour
+		core       bool
+		want       []uuid.UUID
+		wantMerge  bool
+	}{
+		{name: "no visitors", neighbours: []storage.ClusterNeighbour{n(nil, false)}, core: true},
+		{name: "one visitor", neighbours: []storage.ClusterNeighbour{n(nil, false), n(&a, false), n(&a, false)}, core: true, want: []uuid.UUID{a}},
+		{name: "two visitors, not core", neighbours: []storage.ClusterNeighbour{n(&b, false), n(&a, false)}, want: []uuid.UUID{b, a}},
+		{name: "two visitors, core", neighbours: []storage.ClusterNeighbour{n(&b, false), n(&a, false)}, core: true, want: []uuid.UUID{b, a}, wantMerge: true},
+		{name: "one promoted", neighbours: []storage.ClusterNeighbour{n(&a, false), n(&b, true), n(&c, false)}, core: true, want: []uuid.UUID{a, b, c}, wantMerge: true},
+		{name: "two promoted", neighbours: []storage.ClusterNeighbour{n(&a, true), n(&c, false), n(&b, true)}, core: true, want: []uuid.UUID{a, c, b}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, merge := neighbourVisitors(tt.neighbours, tt.core)
+			if !slices.Equal(got, tt.want) || merge != tt.wantMerge {
+				t.Errorf("neighbourVisitors() = %v, %v, want %v, %v", got, merge, tt.want, tt.wantMerge)
+			}
+		})
+	}
+}
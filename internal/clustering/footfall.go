@@ -0,0 +1,192 @@
+package clustering
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/config"
+	"github.com/your-org/fd/internal/models"
+	"github.com/your-org/fd/internal/storage"
+	"github.com/your-org/fd/internal/vision"
+)
+
+// Bucket units of Footfall.
+const (
+	UnitHour = "hour"
+	UnitDay  = "day"
+)
+
+// Footfall counts the unique people seen per hour or day. Tracks are
+// de-duplicated within a bucket by identity where one is known (matched
+// person, then anonymous visitor) and otherwise by embedding similarity, so a
+// person walking past several cameras or returning later counts once.
+// Counts of closed buckets are cached in the database.
+type Footfall struct {
+	db          *storage.PostgresStore
+	maxDistance float64       // cosine distance under which two tracks are the same person
+	settle      time.Duration // how long after a bucket ends its count is cached
+}
+
+// NewFootfall counts with the clustering settings. A bucket's count is cached
+// once its tracks have settled and the next clustering pass has visited them.
+func NewFootfall(db *storage.PostgresStore, cfg config.ClusteringConfig) *Footfall {
+	settle := cfg.SettleDelay
+	if cfg.Interval > 0 {
+		settle += cfg.Interval
+	}
+	return &Footfall{db: db, maxDistance: cfg.MaxDistance, settle: settle}
+}
+
+// BucketStart returns the start of the unit bucket containing t, in loc.
+func BucketStart(t time.Time, unit string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	if unit == UnitHour {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+func nextBucket(start time.Time, unit string) time.Time {
+	if unit == UnitHour {
+		return start.Add(time.Hour)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Buckets returns the footfall of every unit bucket overlapping [from, to),
+// for one stream or, with a nil streamID, across all streams.
+func (f *Footfall) Buckets(ctx context.Context, streamID *uuid.UUID, from, to time.Time, unit string, loc *time.Location) ([]models.FootfallBucket, error) {
+	scope := uuid.Nil
+	if streamID != nil {
+		scope = *streamID
+	}
+	key := fmt.Sprintf("%s|%s|%s|%g", scope, unit, loc, f.maxDistance)
+
+	start := BucketStart(from, unit, loc)
+	var starts []time.Time
+	for b := start; b.Before(to); b = nextBucket(b, unit) {
+		starts = append(starts, b)
+	}
+	end := nextBucket(starts[len(starts)-1], unit)
+
+	cached, err := f.db.GetFootfallCache(ctx, key, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the tracks of the uncached range in one go
+	var missFrom, missTo time.Time
+	for _, b := range starts {
+		if _, ok := cached[b.UTC()]; ok {
+			continue
+		}
+		if missFrom.IsZero() {
+			missFrom = b
+		}
+		missTo = nextBucket(b, unit)
+	}
+	byBucket := make(map[time.Time][]storage.TrackFace)
+	if !missFrom.IsZero() {
+		faces, err := f.db.ListTrackFaces(ctx, streamID, missFrom, missTo)
+		if err != nil {
+			return nil, err
+		}
+		for _, face := range faces {
+			b := BucketStart(face.FirstSeen, unit, loc).UTC()
+			byBucket[b] = append(byBucket[b], face)
+		}
+	}
+
+	closed := time.Now().Add(-f.settle)
+	out := make([]models.FootfallBucket, 0, len(starts))
+	var computed []models.FootfallBucket
+	for _, b := range starts {
+		if fb, ok := cached[b.UTC()]; ok {
+			fb.Start = b
+			out = append(out, fb)
+			continue
+		}
+		fb := f.count(byBucket[b.UTC()])
+		fb.Start = b
+		out = append(out, fb)
+		if !nextBucket(b, unit).After(closed) {
+			computed = append(computed, fb)
+		}
+	}
+	if err := f.db.PutFootfallCache(ctx, key, computed); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// count de-duplicates the tracks of one bucket by greedy leader clustering.
+// Each matched person is one group. A visitor joins the first person group
+// whose leader embedding is close enough, else forms its own group; visitors
+// were already kept apart by the clusterer. Any other track joins the first
+// close group of either kind or leads a new one.
+func (f *Footfall) count(faces []storage.TrackFace) models.FootfallBucket {
+	type group struct {
+		leader  []float32
+		version string
+		known   bool
+	}
+	var groups []*group
+	byIdentity := make(map[uuid.UUID]*group)
+	minSim := float32(1 - f.maxDistance)
+
+	// place adds a track to its identity's group, else to the first close
+	// group accepted by joinable (nil: none), else to a new group.
+	place := func(face storage.TrackFace, id *uuid.UUID, known bool, joinable func(*group) bool) {
+		if id != nil {
+			if _, ok := byIdentity[*id]; ok {
+				return
+			}
+		}
+		var g *group
+		if joinable != nil && face.Embedding != nil {
+			for _, cand := range groups {
+				if joinable(cand) && cand.version == face.ModelVersion && cand.leader != nil &&
+					vision.CosineSimilarity(cand.leader, face.Embedding) >= minSim {
+					g = cand
+					break
+				}
+			}
+		}
+		if g == nil {
+			g = &group{leader: face.Embedding, version: face.ModelVersion, known: known}
+			groups = append(groups, g)
+		}
+		if id != nil {
+			byIdentity[*id] = g
+		}
+	}
+
+	for _, face := range faces {
+		if face.PersonID != nil {
+			place(face, face.PersonID, true, nil)
+		}
+	}
+	for _, face := range faces {
+		if face.PersonID == nil && face.VisitorID != nil {
+			place(face, face.VisitorID, false, func(g *group) bool { return g.known })
+		}
+	}
+	for _, face := range faces {
+		if face.PersonID == nil && face.VisitorID == nil {
+			place(face, nil, false, func(*group) bool { return true })
+		}
+	}
+
+	fb := models.FootfallBucket{UniqueVisitors: len(groups), Tracks: len(faces)}
+	for _, g := range groups {
+		if g.known {
+			fb.Known++
+		} else {
+			fb.Unknown++
+		}
+	}
+	return fb
+}
@@ -10,14 +10,15 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	NATS     NATSConfig     `yaml:"nats"`
-	MinIO    MinIOConfig    `yaml:"minio"`
-	Vision   VisionConfig   `yaml:"vision"`
-	Tracking TrackingConfig `yaml:"tracking"`
-	Storage  StorageConfig  `yaml:"storage"`
-	Logging  LoggingConfig  `yaml:"logging"`
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	NATS       NATSConfig       `yaml:"nats"`
+	MinIO      MinIOConfig      `yaml:"minio"`
+	Vision     VisionConfig     `yaml:"vision"`
+	Tracking   TrackingConfig   `yaml:"tracking"`
+	Clustering ClusteringConfig `yaml:"clustering"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Logging    LoggingConfig    `yaml:"logging"`
 }
 
 type StorageConfig struct {
@@ -25,8 +26,16 @@ type StorageConfig struct {
 }
 
 type ServerConfig struct {
-	Port   int    `yaml:"port"`
-	APIKey string `yaml:"api_key"`
+	Port       int            `yaml:"port"`
+	APIKey     string         `yaml:"api_key"`
+	APIKeys    []APIKeyConfig `yaml:"api_keys"`     // further keys, e.g. for clients that may only see redacted faces
+	LiveMaxFPS float64        `yaml:"live_max_fps"` // frame rate cap of each live preview
+}
+
+// APIKeyConfig is an additional API key.
+type APIKeyConfig struct {
+	Key       string `yaml:"key"`
+	Redaction string `yaml:"redaction"` // faces blurred in frames and snapshots served to this key: "off", "unknown" or "all"
 }
 
 type DatabaseConfig struct {
@@ -56,22 +65,51 @@ type MinIOConfig struct {
 }
 
 type VisionConfig struct {
-	ModelsDir            string  `yaml:"models_dir"`
-	DetectionThreshold   float64 `yaml:"detection_threshold"`
-	RecognitionThreshold float64 `yaml:"recognition_threshold"`
-	DefaultFPS           int     `yaml:"default_fps"`
-	MaxFPS               int     `yaml:"max_fps"`
-	WorkerCount          int     `yaml:"worker_count"`
-	FrameWidth           int     `yaml:"frame_width"`
-	MinFaceSize          int     `yaml:"min_face_size"`
-	IntraOpThreads       int     `yaml:"intra_op_threads"`  // ORT threads per op (0 = auto)
-	InterOpThreads       int     `yaml:"inter_op_threads"`  // ORT threads between ops (0 = auto)
+	ModelsDir            string        `yaml:"models_dir"`
+	DetectionThreshold   float64       `yaml:"detection_threshold"`
+	RecognitionThreshold float64       `yaml:"recognition_threshold"`
+	DefaultFPS           int           `yaml:"default_fps"`
+	MaxFPS               int           `yaml:"max_fps"`
+	WorkerCount          int           `yaml:"worker_count"`
+	FrameWidth           int           `yaml:"frame_width"`
+	MinFaceSize          int           `yaml:"min_face_size"`
+	MinFaceQuality       float32       `yaml:"min_face_quality"`    // live crops below this quality skip recognition (<0 = off)
+	MaxYaw               float32       `yaml:"max_yaw"`             // live crops turned further sideways (degrees) skip recognition (<0 = off)
+	MaxPitch             float32       `yaml:"max_pitch"`           // live crops tilted further up or down (degrees) skip recognition (<0 = off)
+	EnrollMinQuality     float32       `yaml:"enroll_min_quality"`  // AddFace rejects photos below this quality (<0 = off)
+	DetectorInputSize    int           `yaml:"detector_input_size"` // square detector input: 320, 640, 1280, ...
+	DetectorResize       string        `yaml:"detector_resize"`     // "letterbox" (keep aspect) or "stretch"
+	IntraOpThreads       int           `yaml:"intra_op_threads"`    // ORT threads per op (0 = auto)
+	InterOpThreads       int           `yaml:"inter_op_threads"`    // ORT threads between ops (0 = auto)
+	BatchSize            int           `yaml:"batch_size"`          // max faces per embedding/attribute session run
+	BatchWindow          time.Duration `yaml:"batch_window"`        // wait for other streams' faces to share a run (0 = per-frame batches only)
+	Liveness             string        `yaml:"liveness"`            // anti-spoofing: "off", "score" or "enforce"
+	LivenessThreshold    float32       `yaml:"liveness_threshold"`  // faces scoring below are spoofs
+	Redaction            string        `yaml:"redaction"`           // faces blurred in stored frames and snapshots: "off", "unknown" or "all"
+	RedactionStyle       string        `yaml:"redaction_style"`     // "pixelate" or "blur"
 }
 
 type TrackingConfig struct {
-	MaxAge              int           `yaml:"max_age"`
-	MinHits             int           `yaml:"min_hits"`
-	ReRecognizeInterval time.Duration `yaml:"re_recognize_interval"`
+	MaxAge               int           `yaml:"max_age"`           // frames without a detection before a track is dropped
+	MinHits              int           `yaml:"min_hits"`          // consecutive detections before a track is confirmed and emitted
+	IoUThreshold         float64       `yaml:"iou_threshold"`     // min IoU between Kalman prediction and detection
+	ReIDMaxDistance      float64       `yaml:"reid_max_distance"` // max cosine distance to re-attach a new track to a lost one (<0 = off)
+	GallerySize          int           `yaml:"gallery_size"`      // recent embeddings kept per track for re-identification
+	ReRecognizeInterval  time.Duration `yaml:"re_recognize_interval"`
+	IdleTimeout          time.Duration `yaml:"idle_timeout"`           // end all tracks of a stream that sent no frames for this long
+	BestShotMargin       float32       `yaml:"best_shot_margin"`       // quality gain (0..1) needed to replace a track's snapshot
+	IdentityWindow       int           `yaml:"identity_window"`        // recent recognitions voting on a track's identity
+	IdentityMinVotes     int           `yaml:"identity_min_votes"`     // votes a person needs before it is assigned to a track
+	IdentitySwitchMargin float32       `yaml:"identity_switch_margin"` // vote share lead needed to replace an assigned person
+}
+
+// ClusteringConfig controls the API's job that groups unknown tracks into anonymous visitors.
+type ClusteringConfig struct {
+	Interval    time.Duration `yaml:"interval"`     // time between clustering passes (<0 = off)
+	MaxDistance float64       `yaml:"max_distance"` // max cosine distance between neighbouring tracks
+	MinTracks   int           `yaml:"min_tracks"`   // neighbouring tracks, the seed included, that form a new visitor
+	BatchSize   int           `yaml:"batch_size"`   // tracks visited per query round
+	SettleDelay time.Duration `yaml:"settle_delay"` // leave events this recent alone, so tracks are mostly complete
 }
 
 type LoggingConfig struct {
@@ -93,14 +131,51 @@ func Load(path string) (*Config, error) {
 
 	applyEnvOverrides(cfg)
 	setDefaults(cfg)
+	if err := validate(cfg); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
 
+// validate rejects settings that would otherwise silently disable a check or
+// break a feature at runtime.
+func validate(cfg *Config) error {
+	switch cfg.Vision.Liveness {
+	case "off", "score", "enforce":
+	default:
+		return fmt.Errorf("vision.liveness must be off, score or enforce, got %q", cfg.Vision.Liveness)
+	}
+	switch cfg.Vision.Redaction {
+	case "off", "unknown", "all":
+	default:
+		return fmt.Errorf("vision.redaction must be off, unknown or all, got %q", cfg.Vision.Redaction)
+	}
+	switch cfg.Vision.RedactionStyle {
+	case "pixelate", "blur":
+	default:
+		return fmt.Errorf("vision.redaction_style must be pixelate or blur, got %q", cfg.Vision.RedactionStyle)
+	}
+	if cfg.Server.LiveMaxFPS <= 0 {
+		return fmt.Errorf("server.live_max_fps must be positive, got %v", cfg.Server.LiveMaxFPS)
+	}
+	for i, k := range cfg.Server.APIKeys {
+		switch k.Redaction {
+		case "", "off", "unknown", "all":
+		default:
+			return fmt.Errorf("server.api_keys[%d].redaction must be off, unknown or all, got %q", i, k.Redaction)
+		}
+	}
+	return nil
+}
+
 func setDefaults(cfg *Config) {
 	if cfg.Server.Port == 0 {
 		cfg.Server.Port = 8080
 	}
+	if cfg.Server.LiveMaxFPS == 0 {
+		cfg.Server.LiveMaxFPS = 5
+	}
 	if cfg.Database.Port == 0 {
 		cfg.Database.Port = 5432
 	}
@@ -128,6 +203,24 @@ func setDefaults(cfg *Config) {
 	if cfg.Vision.MinFaceSize == 0 {
 		cfg.Vision.MinFaceSize = 40
 	}
+	if cfg.Vision.MinFaceQuality == 0 {
+		cfg.Vision.MinFaceQuality = 0.3
+	}
+	if cfg.Vision.MaxYaw == 0 {
+		cfg.Vision.MaxYaw = 60
+	}
+	if cfg.Vision.MaxPitch == 0 {
+		cfg.Vision.MaxPitch = 45
+	}
+	if cfg.Vision.EnrollMinQuality == 0 {
+		cfg.Vision.EnrollMinQuality = 0.5
+	}
+	if cfg.Vision.DetectorInputSize == 0 {
+		cfg.Vision.DetectorInputSize = 640
+	}
+	if cfg.Vision.DetectorResize == "" {
+		cfg.Vision.DetectorResize = "letterbox"
+	}
 	if cfg.Vision.IntraOpThreads == 0 {
 		// Default: 2 threads per session. With 6 workers × 3 models = 18 sessions,
 		// this caps ORT at ~36 threads total instead of 18×all-cores.
@@ -136,15 +229,69 @@ func setDefaults(cfg *Config) {
 	if cfg.Vision.InterOpThreads == 0 {
 		cfg.Vision.InterOpThreads = 1
 	}
+	if cfg.Vision.BatchSize == 0 {
+		cfg.Vision.BatchSize = 32
+	}
+	if cfg.Vision.Liveness == "" {
+		cfg.Vision.Liveness = "off"
+	}
+	if cfg.Vision.LivenessThreshold == 0 {
+		cfg.Vision.LivenessThreshold = 0.5
+	}
+	if cfg.Vision.Redaction == "" {
+		cfg.Vision.Redaction = "off"
+	}
+	if cfg.Vision.RedactionStyle == "" {
+		cfg.Vision.RedactionStyle = "pixelate"
+	}
 	if cfg.Tracking.MaxAge == 0 {
 		cfg.Tracking.MaxAge = 30
 	}
 	if cfg.Tracking.MinHits == 0 {
 		cfg.Tracking.MinHits = 3
 	}
+	if cfg.Tracking.IoUThreshold == 0 {
+		cfg.Tracking.IoUThreshold = 0.3
+	}
+	if cfg.Tracking.ReIDMaxDistance == 0 {
+		cfg.Tracking.ReIDMaxDistance = 0.45
+	}
+	if cfg.Tracking.GallerySize == 0 {
+		cfg.Tracking.GallerySize = 10
+	}
 	if cfg.Tracking.ReRecognizeInterval == 0 {
 		cfg.Tracking.ReRecognizeInterval = 3 * time.Second
 	}
+	if cfg.Tracking.IdleTimeout == 0 {
+		cfg.Tracking.IdleTimeout = 10 * time.Second
+	}
+	if cfg.Tracking.BestShotMargin == 0 {
+		cfg.Tracking.BestShotMargin = 0.05
+	}
+	if cfg.Tracking.IdentityWindow == 0 {
+		cfg.Tracking.IdentityWindow = 5
+	}
+	if cfg.Tracking.IdentityMinVotes == 0 {
+		cfg.Tracking.IdentityMinVotes = 2
+	}
+	if cfg.Tracking.IdentitySwitchMargin == 0 {
+		cfg.Tracking.IdentitySwitchMargin = 0.2
+	}
+	if cfg.Clustering.Interval == 0 {
+		cfg.Clustering.Interval = 5 * time.Minute
+	}
+	if cfg.Clustering.MaxDistance == 0 {
+		cfg.Clustering.MaxDistance = 0.5
+	}
+	if cfg.Clustering.MinTracks == 0 {
+		cfg.Clustering.MinTracks = 3
+	}
+	if cfg.Clustering.BatchSize == 0 {
+		cfg.Clustering.BatchSize = 500
+	}
+	if cfg.Clustering.SettleDelay == 0 {
+		cfg.Clustering.SettleDelay = time.Minute
+	}
 	if cfg.Logging.Level == "" {
 		cfg.Logging.Level = "info"
 	}
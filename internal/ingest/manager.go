@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,18 +19,20 @@ import (
 
 // StreamCommand represents a start/stop command from the API.
 type StreamCommand struct {
-	Action       string `json:"action"` // start, stop
-	StreamID     string `json:"stream_id"`
-	URL          string `json:"url"`
-	Type         string `json:"type"`
-	Mode         string `json:"mode"`
-	FPS          int    `json:"fps"`
-	CollectionID string `json:"collection_id,omitempty"`
+	Action       string          `json:"action"` // start, stop, configure
+	StreamID     string          `json:"stream_id"`
+	URL          string          `json:"url"`
+	Type         string          `json:"type"`
+	Mode         string          `json:"mode"`
+	FPS          int             `json:"fps"`
+	CollectionID string          `json:"collection_id,omitempty"`
+	Config       json.RawMessage `json:"config,omitempty"` // models.StreamSettings
 }
 
 type activeStream struct {
 	cancel    context.CancelFunc
 	extractor *FFmpegExtractor
+	settings  atomic.Pointer[models.StreamSettings] // swapped by "configure"
 }
 
 // Manager manages video stream ingestion lifecycle.
@@ -60,6 +63,8 @@ func (m *Manager) HandleCommand(ctx context.Context, cmd StreamCommand) error {
 		return m.startStream(ctx, cmd)
 	case "stop":
 		return m.stopStream(cmd.StreamID)
+	case "configure":
+		return m.configureStream(cmd)
 	default:
 		return fmt.Errorf("unknown action: %s", cmd.Action)
 	}
@@ -99,6 +104,13 @@ func (m *Manager) startStream(ctx context.Context, cmd StreamCommand) error {
 		}
 	}
 
+	// Per-stream vision overrides travel with every frame task. The API validates
+	// them on create; a config that no longer parses falls back to the globals.
+	settings, err := models.ParseStreamSettings(cmd.Config)
+	if err != nil {
+		slog.Warn("ignoring stream config", "stream_id", cmd.StreamID, "error", err)
+	}
+
 	streamCtx, cancel := context.WithCancel(ctx)
 	extractor := &FFmpegExtractor{}
 
@@ -106,6 +118,7 @@ func (m *Manager) startStream(ctx context.Context, cmd StreamCommand) error {
 		cancel:    cancel,
 		extractor: extractor,
 	}
+	as.settings.Store(settings)
 
 	m.mu.Lock()
 	m.streams[cmd.StreamID] = as
@@ -178,6 +191,8 @@ func (m *Manager) startStream(ctx context.Context, cmd StreamCommand) error {
 					Width:        m.width,
 					Height:       0, // Will be determined by worker
 					CollectionID: collectionID,
+					Mode:         models.StreamMode(cmd.Mode),
+					Settings:     as.settings.Load(),
 				}
 
 				if err := m.producer.PublishFrame(streamCtx, cmd.StreamID, task); err != nil {
@@ -224,6 +239,28 @@ func (m *Manager) stopStream(streamID string) error {
 	return nil
 }
 
+// configureStream replaces the settings of a running stream; frames published
+// from then on carry the new config. Streams that are not running here pick the
+// config up from the database on their next start.
+func (m *Manager) configureStream(cmd StreamCommand) error {
+	m.mu.RLock()
+	as, exists := m.streams[cmd.StreamID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil
+	}
+
+	settings, err := models.ParseStreamSettings(cmd.Config)
+	if err != nil {
+		return err
+	}
+	as.settings.Store(settings)
+
+	slog.Info("stream config updated", "stream_id", cmd.StreamID)
+	return nil
+}
+
 func (m *Manager) updateStatus(streamID string, status models.StreamStatus, errMsg string) {
 	id, err := uuid.Parse(streamID)
 	if err != nil {
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AnalyticsKind string
+
+const (
+	AnalyticsLine AnalyticsKind = "line"
+	AnalyticsZone AnalyticsKind = "zone"
+)
+
+// Tripwire is a directed counting line in normalised frame coordinates. A track
+// whose centroid crosses it from the left of From→To to the right (as seen on
+// screen) counts as "in", the other way as "out".
+type Tripwire struct {
+	Name string     `json:"name"`
+	From [2]float64 `json:"from"`
+	To   [2]float64 `json:"to"`
+}
+
+// Zone is a named area whose occupancy, entries and exits are counted.
+type Zone struct {
+	Name    string  `json:"name"`
+	Polygon Polygon `json:"polygon"`
+}
+
+// AnalyticsCount is the change of one tripwire or zone counter.
+type AnalyticsCount struct {
+	Kind      AnalyticsKind `json:"kind"`
+	Name      string        `json:"name"`
+	In        int           `json:"in"`        // line: crossings in; zone: entries
+	Out       int           `json:"out"`       // line: crossings out; zone: exits
+	Occupancy int           `json:"occupancy"` // zone: tracks inside after the change
+}
+
+// AnalyticsEvent is published by the vision worker when tripwire or zone
+// counters of a stream change.
+type AnalyticsEvent struct {
+	StreamID  uuid.UUID        `json:"stream_id"`
+	Timestamp time.Time        `json:"timestamp"`
+	Counts    []AnalyticsCount `json:"counts"`
+}
+
+// AnalyticsBucket is the persisted count of one tripwire or zone over a time bucket.
+type AnalyticsBucket struct {
+	StreamID      uuid.UUID     `json:"stream_id" db:"stream_id"`
+	Bucket        time.Time     `json:"bucket" db:"bucket"`
+	Kind          AnalyticsKind `json:"kind" db:"kind"`
+	Name          string        `json:"name" db:"name"`
+	In            int           `json:"in" db:"in_count"`
+	Out           int           `json:"out" db:"out_count"`
+	Occupancy     int           `json:"occupancy" db:"occupancy"` // zone: at the last change in the bucket
+	PeakOccupancy int           `json:"peak_occupancy" db:"peak_occupancy"`
+}
+
+func validateTripwires(lines []Tripwire) error {
+	seen := make(map[string]bool)
+	for i, l := range lines {
+		if err := validateCounterName(l.Name, seen); err != nil {
+			return fmt.Errorf("tripwires[%d]: %w", i, err)
+		}
+		for _, pt := range [][2]float64{l.From, l.To} {
+			if pt[0] < 0 || pt[0] > 1 || pt[1] < 0 || pt[1] > 1 {
+				return fmt.Errorf("tripwires[%d]: point %v is outside [0, 1]", i, pt)
+			}
+		}
+		if l.From == l.To {
+			return fmt.Errorf("tripwires[%d]: from and to must differ", i)
+		}
+	}
+	return nil
+}
+
+func validateZones(zones []Zone) error {
+	seen := make(map[string]bool)
+	for i, z := range zones {
+		if err := validateCounterName(z.Name, seen); err != nil {
+			return fmt.Errorf("zones[%d]: %w", i, err)
+		}
+		if err := z.Polygon.validate(); err != nil {
+			return fmt.Errorf("zones[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateCounterName(name string, seen map[string]bool) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("name is required")
+	case len(name) > 100:
+		return fmt.Errorf("name is longer than 100 characters")
+	case seen[name]:
+		return fmt.Errorf("duplicate name %q", name)
+	}
+	seen[name] = true
+	return nil
+}
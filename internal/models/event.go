@@ -7,48 +7,121 @@ import (
 )
 
 type Event struct {
-	ID               uuid.UUID  `json:"id" db:"id"`
-	StreamID         uuid.UUID  `json:"stream_id" db:"stream_id"`
-	TrackID          string     `json:"track_id" db:"track_id"`
-	Timestamp        time.Time  `json:"timestamp" db:"timestamp"`
-	Gender           string     `json:"gender" db:"gender"`
-	GenderConfidence float32    `json:"gender_confidence" db:"gender_confidence"`
-	Age              int        `json:"age" db:"age"`
-	AgeRange         string     `json:"age_range" db:"age_range"`
-	Confidence       float32    `json:"confidence" db:"confidence"`
-	Embedding        []float32  `json:"-" db:"embedding"`
-	MatchedPersonID  *uuid.UUID `json:"matched_person_id,omitempty" db:"matched_person_id"`
-	MatchScore       float32    `json:"match_score,omitempty" db:"match_score"`
-	SnapshotKey      string     `json:"snapshot_key" db:"snapshot_key"`
-	FrameKey         string     `json:"frame_key" db:"frame_key"` // MinIO key of the full frame
-	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	ID               uuid.UUID      `json:"id" db:"id"`
+	StreamID         uuid.UUID      `json:"stream_id" db:"stream_id"`
+	TrackID          string         `json:"track_id" db:"track_id"`
+	Timestamp        time.Time      `json:"timestamp" db:"timestamp"`
+	BBox             []float32      `json:"bbox,omitempty" db:"bbox"`           // x1, y1, x2, y2; nil for events stored without one
+	Landmarks        []float32      `json:"landmarks,omitempty" db:"landmarks"` // x, y of the 5 landmarks (eyes, nose, mouth corners)
+	Gender           string         `json:"gender" db:"gender"`
+	GenderConfidence float32        `json:"gender_confidence" db:"gender_confidence"`
+	Age              int            `json:"age" db:"age"`
+	AgeRange         string         `json:"age_range" db:"age_range"`
+	AttributeSamples int            `json:"attribute_samples" db:"attribute_samples"`
+	Confidence       float32        `json:"confidence" db:"confidence"`
+	Embedding        []float32      `json:"-" db:"embedding"`
+	ModelVersion     string         `json:"model_version" db:"model_version"` // embedder that produced Embedding
+	MatchedPersonID  *uuid.UUID     `json:"matched_person_id,omitempty" db:"matched_person_id"`
+	MatchScore       float32        `json:"match_score,omitempty" db:"match_score"`
+	InstantPersonID  *uuid.UUID     `json:"instant_person_id,omitempty" db:"instant_person_id"`
+	InstantScore     float32        `json:"instant_score,omitempty" db:"instant_score"`
+	SnapshotKey      string         `json:"snapshot_key" db:"snapshot_key"`
+	FrameKey         string         `json:"frame_key" db:"frame_key"` // MinIO key of the full frame
+	QualityScore     float32        `json:"quality_score" db:"quality_score"`
+	VisitorID        *uuid.UUID     `json:"visitor_id,omitempty" db:"visitor_id"` // anonymous identity of an unknown face
+	LivenessScore    *float32       `json:"liveness_score,omitempty" db:"liveness_score"`
+	Spoof            bool           `json:"spoof,omitempty" db:"spoof"`
+	Attributes       FaceAttributes `json:"attributes,omitempty" db:"attributes"`
+	Pose             *HeadPose      `json:"pose,omitempty" db:"-"` // yaw, pitch and roll columns; nil for events stored before pose estimation
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
 }
 
 // FrameTask is the message published to NATS for worker processing.
 type FrameTask struct {
-	StreamID     uuid.UUID  `json:"stream_id"`
-	FrameID      uuid.UUID  `json:"frame_id"`
-	Timestamp    time.Time  `json:"timestamp"`
-	FrameRef     string     `json:"frame_ref"` // MinIO object key
-	Width        int        `json:"width"`
-	Height       int        `json:"height"`
-	CollectionID *uuid.UUID `json:"collection_id,omitempty"` // stream's collection for scoped search
+	StreamID     uuid.UUID       `json:"stream_id"`
+	FrameID      uuid.UUID       `json:"frame_id"`
+	Timestamp    time.Time       `json:"timestamp"`
+	FrameRef     string          `json:"frame_ref"` // MinIO object key
+	Width        int             `json:"width"`
+	Height       int             `json:"height"`
+	CollectionID *uuid.UUID      `json:"collection_id,omitempty"` // stream's collection for scoped search
+	Mode         StreamMode      `json:"mode,omitempty"`          // identify: only faces matched in CollectionID are reported
+	Settings     *StreamSettings `json:"settings,omitempty"`      // per-stream overrides of the vision/tracking config
+}
+
+// LiveFrame tells live preview viewers that a worker has processed a stored
+// frame, with what it found there.
+type LiveFrame struct {
+	StreamID  uuid.UUID    `json:"stream_id"`
+	Timestamp time.Time    `json:"timestamp"`
+	FrameRef  string       `json:"frame_ref"` // MinIO object key, already redacted as the stream requires
+	Faces     [][4]float32 `json:"faces"`     // every detected face, also those not tracked, for redaction
+	Tracks    []LiveTrack  `json:"tracks"`    // confirmed tracks matched in this frame
+}
+
+// LiveTrack is a tracked face of a LiveFrame.
+type LiveTrack struct {
+	TrackID    string        `json:"track_id"`
+	BBox       [4]float32    `json:"bbox"`
+	Landmarks  [5][2]float32 `json:"landmarks"`
+	PersonID   *uuid.UUID    `json:"person_id,omitempty"` // consolidated identity
+	MatchScore float32       `json:"match_score"`
+	Gender     string        `json:"gender"`
+	Age        int           `json:"age"`
 }
 
 // DetectionResult is the output from a vision worker for one face.
 type DetectionResult struct {
-	StreamID         uuid.UUID  `json:"stream_id"`
-	TrackID          string     `json:"track_id"`
-	Timestamp        time.Time  `json:"timestamp"`
-	BBox             [4]float32 `json:"bbox"` // x1, y1, x2, y2
-	Gender           string     `json:"gender"`
-	GenderConfidence float32    `json:"gender_confidence"`
-	Age              int        `json:"age"`
-	AgeRange         string     `json:"age_range"`
-	Confidence       float32    `json:"confidence"`
-	Embedding        []float32  `json:"embedding"`
-	MatchedPersonID  *uuid.UUID `json:"matched_person_id,omitempty"`
-	MatchScore       float32    `json:"match_score,omitempty"`
-	SnapshotKey      string     `json:"snapshot_key"`
-	FrameKey         string     `json:"frame_key"` // MinIO key of the full frame
+	StreamID         uuid.UUID      `json:"stream_id"`
+	TrackID          string         `json:"track_id"`
+	Timestamp        time.Time      `json:"timestamp"`
+	BBox             [4]float32     `json:"bbox"`      // x1, y1, x2, y2
+	Landmarks        [5][2]float32  `json:"landmarks"` // left eye, right eye, nose tip, left and right mouth corner
+	Gender           string         `json:"gender"`
+	GenderConfidence float32        `json:"gender_confidence"`
+	Age              int            `json:"age"`
+	AgeRange         string         `json:"age_range"`
+	AttributeSamples int            `json:"attribute_samples"` // predictions the smoothed gender/age are based on
+	Confidence       float32        `json:"confidence"`
+	Embedding        []float32      `json:"embedding"`
+	ModelVersion     string         `json:"model_version"`               // embedder that produced Embedding
+	MatchedPersonID  *uuid.UUID     `json:"matched_person_id,omitempty"` // consolidated identity of the track
+	MatchScore       float32        `json:"match_score,omitempty"`
+	InstantPersonID  *uuid.UUID     `json:"instant_person_id,omitempty"` // top match of this recognition pass alone
+	InstantScore     float32        `json:"instant_score,omitempty"`
+	SnapshotKey      string         `json:"snapshot_key"`             // the track's best shot so far
+	FrameKey         string         `json:"frame_key"`                // MinIO key of the full frame
+	QualityScore     float32        `json:"quality_score"`            // quality of the crop this result was computed from
+	BestShot         bool           `json:"best_shot,omitempty"`      // SnapshotKey was replaced by this result's crop
+	LivenessScore    *float32       `json:"liveness_score,omitempty"` // probability the crop is a live face; nil if not checked
+	Spoof            bool           `json:"spoof,omitempty"`          // LivenessScore is below the stream's liveness threshold
+	Attributes       FaceAttributes `json:"attributes,omitempty"`     // track-smoothed face attribute classifiers
+	Pose             HeadPose       `json:"pose"`                     // head pose of the crop this result was computed from
+}
+
+// HeadPose is a face's orientation in degrees, 0 when looking straight at the
+// camera: yaw positive turned towards the image's right, pitch positive looking
+// up, roll positive tilted clockwise.
+type HeadPose struct {
+	Yaw   float32 `json:"yaw"`
+	Pitch float32 `json:"pitch"`
+	Roll  float32 `json:"roll"`
+}
+
+// FaceAttribute is the label one face attribute classifier gives a track, e.g.
+// "mask": {"label": "mask", "confidence": 0.97, "occluding": true}.
+type FaceAttribute struct {
+	Label      string  `json:"label"`
+	Confidence float32 `json:"confidence"`
+	Occluding  bool    `json:"occluding,omitempty"` // the label hides part of the face, lowering reported match scores
+}
+
+// FaceAttributes maps classifier names (mask, eyewear, hat, ...) to their labels.
+type FaceAttributes map[string]FaceAttribute
+
+// AttributeBucket counts the tracks given one label of a face attribute in a time bucket.
+type AttributeBucket struct {
+	Bucket time.Time `json:"bucket"`
+	Label  string    `json:"label"`
+	Tracks int       `json:"tracks"`
 }
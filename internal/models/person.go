@@ -17,10 +17,53 @@ type Person struct {
 }
 
 type FaceEmbedding struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	PersonID  uuid.UUID `json:"person_id" db:"person_id"`
-	Embedding []float32 `json:"embedding" db:"embedding"`
-	Quality   float32   `json:"quality" db:"quality"`
-	SourceKey string    `json:"source_key" db:"source_key"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID           uuid.UUID `json:"id" db:"id"`
+	PersonID     uuid.UUID `json:"person_id" db:"person_id"`
+	Embedding    []float32 `json:"embedding" db:"embedding"`
+	ModelVersion string    `json:"model_version" db:"model_version"` // embedder that produced Embedding
+	Quality      float32   `json:"quality" db:"quality"`
+	SourceKey    string    `json:"source_key" db:"source_key"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReembedStatus reports the progress of the job that re-embeds enrollment
+// faces with the current embedding model.
+type ReembedStatus struct {
+	Running      bool       `json:"running"`
+	ModelVersion string     `json:"model_version"`
+	Pending      int        `json:"pending"`   // faces of other versions when the run started
+	Processed    int        `json:"processed"` // updated + failed
+	Updated      int        `json:"updated"`
+	Failed       int        `json:"failed"` // source image missing or no face found; retried on the next run
+	LastError    string     `json:"last_error,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// Face quality reasons reported when a component of FaceQuality is poor.
+const (
+	QualityLowConfidence = "low_confidence"
+	QualityTooSmall      = "too_small"
+	QualityBlurry        = "blurry"
+	QualityUnderexposed  = "underexposed"
+	QualityOverexposed   = "overexposed"
+	QualityYaw           = "yaw_too_large"
+	QualityPitch         = "pitch_too_large"
+	QualityOccluded      = "occluded"
+)
+
+// FaceQuality is the assessed quality of one face crop. Every component is in
+// [0, 1] with 1 being best; Score combines them. Reasons lists the components
+// that fall below their acceptable level.
+type FaceQuality struct {
+	Score      float32  `json:"score"`
+	Confidence float32  `json:"confidence"` // detector confidence
+	Size       float32  `json:"size"`       // face size relative to the recognizer's input
+	Sharpness  float32  `json:"sharpness"`
+	Exposure   float32  `json:"exposure"`
+	Yaw        float32  `json:"yaw"`       // 1 = not turned sideways
+	Pitch      float32  `json:"pitch"`     // 1 = not tilted up or down
+	Occlusion  float32  `json:"occlusion"` // 1 = landmark layout consistent with an unoccluded face
+	Pose       HeadPose `json:"pose"`      // head pose solved from the landmarks, Yaw and Pitch are scored from it
+	Reasons    []string `json:"reasons,omitempty"`
 }
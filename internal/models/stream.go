@@ -1,7 +1,9 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +24,62 @@ const (
 	StreamModeIdentify StreamMode = "identify"
 )
 
+// LivenessMode selects the anti-spoofing check of a stream's faces.
+type LivenessMode string
+
+const (
+	LivenessOff     LivenessMode = "off"
+	LivenessScore   LivenessMode = "score"   // report liveness_score and spoof
+	LivenessEnforce LivenessMode = "enforce" // also never match or report spoofs as a person
+)
+
+// RedactionMode selects the faces obscured in stored and served frames and snapshots.
+type RedactionMode string
+
+const (
+	RedactionOff     RedactionMode = "off"
+	RedactionUnknown RedactionMode = "unknown" // every face except matched persons
+	RedactionAll     RedactionMode = "all"
+)
+
+// RedactedImageSuffix ends the object keys of frames and snapshots stored with
+// faces obscured, so they are never used as an enrollment source.
+const RedactedImageSuffix = ".redacted.jpg"
+
+// Valid reports whether m is a known mode.
+func (m RedactionMode) Valid() bool {
+	return m == RedactionOff || m == RedactionUnknown || m == RedactionAll
+}
+
+// ParseRedactionMode parses a configured redaction mode, "" being off.
+// Unrecognised values redact every face, so a typo never leaves faces in the clear.
+func ParseRedactionMode(s string) RedactionMode {
+	if s == "" {
+		return RedactionOff
+	}
+	if m := RedactionMode(s); m.Valid() {
+		return m
+	}
+	return RedactionAll
+}
+
+// Stricter returns the mode of m and o that redacts more faces.
+func (m RedactionMode) Stricter(o RedactionMode) RedactionMode {
+	rank := map[RedactionMode]int{RedactionUnknown: 1, RedactionAll: 2}
+	if rank[o] > rank[m] {
+		return o
+	}
+	return m
+}
+
+// RedactionStyle is how a redacted face is obscured.
+type RedactionStyle string
+
+const (
+	RedactionPixelate RedactionStyle = "pixelate"
+	RedactionBlur     RedactionStyle = "blur"
+)
+
 type StreamStatus string
 
 const (
@@ -44,3 +102,196 @@ type Stream struct {
 	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time       `json:"updated_at" db:"updated_at"`
 }
+
+// StreamSettings overrides the global vision and tracking settings for one
+// stream. It is the typed form of Stream.Config; nil fields keep the global value.
+type StreamSettings struct {
+	DetectionThreshold   *float64        `json:"detection_threshold,omitempty"`
+	RecognitionThreshold *float64        `json:"recognition_threshold,omitempty"`
+	MinFaceSize          *int            `json:"min_face_size,omitempty"`
+	MinFaceQuality       *float32        `json:"min_face_quality,omitempty"` // <0 = off
+	MaxYaw               *float32        `json:"max_yaw,omitempty"`          // degrees, <0 = off
+	MaxPitch             *float32        `json:"max_pitch,omitempty"`        // degrees, <0 = off
+	ReRecognizeInterval  *Duration       `json:"re_recognize_interval,omitempty"`
+	Liveness             *LivenessMode   `json:"liveness,omitempty"`
+	LivenessThreshold    *float32        `json:"liveness_threshold,omitempty"`
+	Redaction            *RedactionMode  `json:"redaction,omitempty"`
+	RedactionStyle       *RedactionStyle `json:"redaction_style,omitempty"`
+
+	MaxAge               *int     `json:"max_age,omitempty"`
+	MinHits              *int     `json:"min_hits,omitempty"`
+	IoUThreshold         *float64 `json:"iou_threshold,omitempty"`
+	ReIDMaxDistance      *float64 `json:"reid_max_distance,omitempty"` // <0 = off
+	BestShotMargin       *float32 `json:"best_shot_margin,omitempty"`
+	IdentityWindow       *int     `json:"identity_window,omitempty"`
+	IdentityMinVotes     *int     `json:"identity_min_votes,omitempty"`
+	IdentitySwitchMargin *float32 `json:"identity_switch_margin,omitempty"`
+
+	// Regions of interest. A face is processed only if its bbox centre lies in
+	// some ROI (when any are set) and in no exclusion polygon.
+	ROI     []Polygon `json:"roi,omitempty"`
+	Exclude []Polygon `json:"exclude,omitempty"`
+
+	// Counting primitives evaluated on track centroids, see AnalyticsEvent.
+	Tripwires []Tripwire `json:"tripwires,omitempty"`
+	Zones     []Zone     `json:"zones,omitempty"`
+}
+
+// Polygon is a closed polygon in normalised frame coordinates: each point is
+// [x, y] with 0..1 spanning the frame width and height, so regions survive a
+// change of frame_width.
+type Polygon [][2]float64
+
+// Contains reports whether the normalised point (x, y) lies inside p
+// (even-odd rule).
+func (p Polygon) Contains(x, y float64) bool {
+	in := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		xi, yi := p[i][0], p[i][1]
+		xj, yj := p[j][0], p[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			in = !in
+		}
+	}
+	return in
+}
+
+func (p Polygon) validate() error {
+	if len(p) < 3 {
+		return fmt.Errorf("needs at least 3 points")
+	}
+	for _, pt := range p {
+		if pt[0] < 0 || pt[0] > 1 || pt[1] < 0 || pt[1] > 1 {
+			return fmt.Errorf("point %v is outside [0, 1]", pt)
+		}
+	}
+	return nil
+}
+
+// AllowsPoint reports whether a face centred at the normalised point (x, y)
+// passes the stream's ROI and exclusion polygons.
+func (s *StreamSettings) AllowsPoint(x, y float64) bool {
+	if s == nil {
+		return true
+	}
+	for _, p := range s.Exclude {
+		if p.Contains(x, y) {
+			return false
+		}
+	}
+	if len(s.ROI) == 0 {
+		return true
+	}
+	for _, p := range s.ROI {
+		if p.Contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseStreamSettings decodes and validates a stream config. Unknown keys are
+// rejected. Returns nil for an empty config.
+func ParseStreamSettings(raw json.RawMessage) (*StreamSettings, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte("{}")) {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.DisallowUnknownFields()
+	var s StreamSettings
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid stream config: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid stream config: %w", err)
+	}
+	return &s, nil
+}
+
+// Validate checks that every set field is in range.
+func (s *StreamSettings) Validate() error {
+	switch {
+	case s.DetectionThreshold != nil && (*s.DetectionThreshold <= 0 || *s.DetectionThreshold > 1):
+		return fmt.Errorf("detection_threshold must be in (0, 1]")
+	case s.RecognitionThreshold != nil && (*s.RecognitionThreshold <= 0 || *s.RecognitionThreshold > 1):
+		return fmt.Errorf("recognition_threshold must be in (0, 1]")
+	case s.MinFaceSize != nil && *s.MinFaceSize < 0:
+		return fmt.Errorf("min_face_size must not be negative")
+	case s.MinFaceQuality != nil && *s.MinFaceQuality > 1:
+		return fmt.Errorf("min_face_quality must be at most 1")
+	case s.MaxYaw != nil && *s.MaxYaw > 90:
+		return fmt.Errorf("max_yaw must be at most 90")
+	case s.MaxPitch != nil && *s.MaxPitch > 90:
+		return fmt.Errorf("max_pitch must be at most 90")
+	case s.Liveness != nil && *s.Liveness != LivenessOff && *s.Liveness != LivenessScore && *s.Liveness != LivenessEnforce:
+		return fmt.Errorf("liveness must be off, score or enforce")
+	case s.LivenessThreshold != nil && (*s.LivenessThreshold <= 0 || *s.LivenessThreshold >= 1):
+		return fmt.Errorf("liveness_threshold must be in (0, 1)")
+	case s.Redaction != nil && !s.Redaction.Valid():
+		return fmt.Errorf("redaction must be off, unknown or all")
+	case s.RedactionStyle != nil && *s.RedactionStyle != RedactionPixelate && *s.RedactionStyle != RedactionBlur:
+		return fmt.Errorf("redaction_style must be pixelate or blur")
+	case s.ReRecognizeInterval != nil && *s.ReRecognizeInterval < 0:
+		return fmt.Errorf("re_recognize_interval must not be negative")
+	case s.MaxAge != nil && *s.MaxAge < 1:
+		return fmt.Errorf("max_age must be at least 1")
+	case s.MinHits != nil && *s.MinHits < 1:
+		return fmt.Errorf("min_hits must be at least 1")
+	case s.IoUThreshold != nil && (*s.IoUThreshold <= 0 || *s.IoUThreshold >= 1):
+		return fmt.Errorf("iou_threshold must be in (0, 1)")
+	case s.ReIDMaxDistance != nil && *s.ReIDMaxDistance > 2:
+		return fmt.Errorf("reid_max_distance must be at most 2")
+	case s.BestShotMargin != nil && *s.BestShotMargin < 0:
+		return fmt.Errorf("best_shot_margin must not be negative")
+	case s.IdentityWindow != nil && *s.IdentityWindow < 1:
+		return fmt.Errorf("identity_window must be at least 1")
+	case s.IdentityMinVotes != nil && *s.IdentityMinVotes < 1:
+		return fmt.Errorf("identity_min_votes must be at least 1")
+	case s.IdentityWindow != nil && s.IdentityMinVotes != nil && *s.IdentityMinVotes > *s.IdentityWindow:
+		return fmt.Errorf("identity_min_votes must not exceed identity_window")
+	case s.IdentitySwitchMargin != nil && (*s.IdentitySwitchMargin < 0 || *s.IdentitySwitchMargin >= 1):
+		return fmt.Errorf("identity_switch_margin must be in [0, 1)")
+	}
+	for i, p := range s.ROI {
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("roi[%d]: %w", i, err)
+		}
+	}
+	for i, p := range s.Exclude {
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("exclude[%d]: %w", i, err)
+		}
+	}
+	if err := validateTripwires(s.Tripwires); err != nil {
+		return err
+	}
+	return validateZones(s.Zones)
+}
+
+// Duration is a time.Duration that is written as a Go duration string ("3s")
+// in JSON. Plain numbers are read as nanoseconds.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("duration must be a string like \"3s\"")
+		}
+		*d = Duration(n)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
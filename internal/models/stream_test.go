@@ -0,0 +1,218 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStreamSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantNil bool
+		wantErr string // substring, "" = no error
+	}{
+		{name: "empty", raw: "", wantNil: true},
+		{name: "null", raw: "null", wantNil: true},
+		{name: "empty object", raw: " {} ", wantNil: true},
+		{name: "overrides", raw: `{"detection_threshold": 0.6, "min_hits": 2, "re_recognize_interval": "3s"}`},
+		{name: "unknown key", raw: `{"detection_treshold": 0.6}`, wantErr: "unknown field"},
+		{name: "wrong type", raw: `{"min_hits": "2"}`, wantErr: "invalid stream config"},
+		{name: "bad duration", raw: `{"re_recognize_interval": "soon"}`, wantErr: "invalid stream config"},
+		{name: "out of range", raw: `{"detection_threshold": 1.5}`, wantErr: "detection_threshold"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseStreamSettings(json.RawMessage(tt.raw))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (s == nil) != tt.wantNil {
+				t.Fatalf("settings = %+v, want nil: %v", s, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestParseStreamSettingsValues(t *testing.T) {
+	s, err := ParseStreamSettings(json.RawMessage(`{"detection_threshold": 0.6, "re_recognize_interval": "3s", "max_age": 10}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.DetectionThreshold == nil || *s.DetectionThreshold != 0.6 {
+		t.Errorf("detection_threshold = %v, want 0.6", s.DetectionThreshold)
+	}
+	if s.ReRecognizeInterval == nil || time.Duration(*s.ReRecognizeInterval) != 3*time.Second {
+		t.Errorf("re_recognize_interval = %v, want 3s", s.ReRecognizeInterval)
+	}
+	if s.MaxAge == nil || *s.MaxAge != 10 {
+		t.Errorf("max_age = %v, want 10", s.MaxAge)
+	}
+	if s.MinHits != nil {
+		t.Errorf("min_hits = %v, want unset", *s.MinHits)
+	}
+}
+
+func TestStreamSettingsValidate(t *testing.T) {
+	f64 := func(v float64) *float64 { return &v }
+	f32 := func(v float32) *float32 { return &v }
+	i := func(v int) *int { return &v }
+	d := func(v time.Duration) *Duration { x := Duration(v); return &x }
+
+	tests := []struct {
+		name    string
+		s       StreamSettings
+		wantErr string // substring, "" = valid
+	}{
+		{name: "nothing set", s: StreamSettings{}},
+		{name: "detection threshold 1", s: StreamSettings{DetectionThreshold: f64(1)}},
+		{name: "detection threshold 0", s: StreamSettings{DetectionThreshold: f64(0)}, wantErr: "detection_threshold"},
+		{name: "recognition threshold above 1", s: StreamSettings{RecognitionThreshold: f64(1.1)}, wantErr: "recognition_threshold"},
+		{name: "negative min face size", s: StreamSettings{MinFaceSize: i(-1)}, wantErr: "min_face_size"},
+		{name: "quality gate off", s: StreamSettings{MinFaceQuality: f32(-1)}},
+		{name: "negative re-recognize interval", s: StreamSettings{ReRecognizeInterval: d(-time.Second)}, wantErr: "re_recognize_interval"},
+		{name: "max age 0", s: StreamSettings{MaxAge: i(0)}, wantErr: "max_age"},
+		{name: "min hits 0", s: StreamSettings{MinHits: i(0)}, wantErr: "min_hits"},
+		{name: "iou threshold 1", s: StreamSettings{IoUThreshold: f64(1)}, wantErr: "iou_threshold"},
+		{name: "re-id off", s: StreamSettings{ReIDMaxDistance: f64(-1)}},
+		{name: "re-id distance above 2", s: StreamSettings{ReIDMaxDistance: f64(2.5)}, wantErr: "reid_max_distance"},
+		{name: "negative best shot margin", s: StreamSettings{BestShotMargin: f32(-0.1)}, wantErr: "best_shot_margin"},
+		{name: "votes within window", s: StreamSettings{IdentityWindow: i(5), IdentityMinVotes: i(5)}},
+		{name: "votes exceed window", s: StreamSettings{IdentityWindow: i(3), IdentityMinVotes: i(4)}, wantErr: "identity_min_votes"},
+		{name: "switch margin 1", s: StreamSettings{IdentitySwitchMargin: f32(1)}, wantErr: "identity_switch_margin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.s.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	square := Polygon{{0.2, 0.2}, {0.8, 0.2}, {0.8, 0.8}, {0.2, 0.8}}
+	// An L shape: the square minus its top-right quarter
+	ell := Polygon{{0, 0}, {0.5, 0}, {0.5, 0.5}, {1, 0.5}, {1, 1}, {0, 1}}
+
+	tests := []struct {
+		name string
+		p    Polygon
+		x, y float64
+		want bool
+	}{
+		{name: "square centre", p: square, x: 0.5, y: 0.5, want: true},
+		{name: "square left of it", p: square, x: 0.1, y: 0.5, want: false},
+		{name: "square below it", p: square, x: 0.5, y: 0.9, want: false},
+		{name: "square near a corner", p: square, x: 0.21, y: 0.79, want: true},
+		{name: "L lower arm", p: ell, x: 0.9, y: 0.9, want: true},
+		{name: "L upper arm", p: ell, x: 0.1, y: 0.1, want: true},
+		{name: "L cut-out quarter", p: ell, x: 0.9, y: 0.1, want: false},
+		{name: "empty polygon", p: nil, x: 0.5, y: 0.5, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.Contains(tt.x, tt.y); got != tt.want {
+				t.Errorf("Contains(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowsPoint(t *testing.T) {
+	left := Polygon{{0, 0}, {0.5, 0}, {0.5, 1}, {0, 1}}
+	topLeft := Polygon{{0, 0}, {0.25, 0}, {0.25, 0.25}, {0, 0.25}}
+
+	tests := []struct {
+		name string
+		s    *StreamSettings
+		x, y float64
+		want bool
+	}{
+		{name: "no settings", s: nil, x: 0.9, y: 0.9, want: true},
+		{name: "no regions", s: &StreamSettings{}, x: 0.9, y: 0.9, want: true},
+		{name: "inside roi", s: &StreamSettings{ROI: []Polygon{left}}, x: 0.3, y: 0.5, want: true},
+		{name: "outside roi", s: &StreamSettings{ROI: []Polygon{left}}, x: 0.7, y: 0.5, want: false},
+		{name: "excluded", s: &StreamSettings{Exclude: []Polygon{topLeft}}, x: 0.1, y: 0.1, want: false},
+		{name: "not excluded", s: &StreamSettings{Exclude: []Polygon{topLeft}}, x: 0.7, y: 0.1, want: true},
+		{name: "exclusion wins over roi", s: &StreamSettings{ROI: []Polygon{left}, Exclude: []Polygon{topLeft}}, x: 0.1, y: 0.1, want: false},
+		{name: "roi minus exclusion", s: &StreamSettings{ROI: []Polygon{left}, Exclude: []Polygon{topLeft}}, x: 0.3, y: 0.6, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.AllowsPoint(tt.x, tt.y); got != tt.want {
+				t.Errorf("AllowsPoint(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamSettingsValidateRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       StreamSettings
+		wantErr string
+	}{
+		{name: "valid roi", s: StreamSettings{ROI: []Polygon{{{0, 0}, {1, 0}, {1, 1}}}}},
+		{name: "too few points", s: StreamSettings{ROI: []Polygon{{{0, 0}, {1, 1}}}}, wantErr: "roi[0]: needs at least 3 points"},
+		{name: "point outside frame", s: StreamSettings{Exclude: []Polygon{{{0, 0}, {1.2, 0}, {1, 1}}}}, wantErr: "exclude[0]: point"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.s.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedactionMode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want RedactionMode
+	}{
+		{in: "", want: RedactionOff},
+		{in: "off", want: RedactionOff},
+		{in: "unknown", want: RedactionUnknown},
+		{in: "all", want: RedactionAll},
+		{in: "Unknown", want: RedactionAll},
+		{in: "blur", want: RedactionAll},
+	}
+	for _, tt := range tests {
+		if got := ParseRedactionMode(tt.in); got != tt.want {
+			t.Errorf("ParseRedactionMode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	modes := []RedactionMode{RedactionOff, RedactionUnknown, RedactionAll}
+	for i, m := range modes {
+		for j, o := range modes {
+			want := modes[max(i, j)]
+			if got := m.Stricter(o); got != want {
+				t.Errorf("%q.Stricter(%q) = %q, want %q", m, o, got, want)
+			}
+		}
+	}
+}
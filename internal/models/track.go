@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TrackEventType string
+
+const (
+	TrackStarted TrackEventType = "track_started"
+	TrackUpdated TrackEventType = "track_updated"
+	TrackEnded   TrackEventType = "track_ended"
+)
+
+type TrackStatus string
+
+const (
+	TrackStatusActive TrackStatus = "active"
+	TrackStatusEnded  TrackStatus = "ended"
+)
+
+// Track is the persisted lifecycle summary of one tracked face.
+type Track struct {
+	ID           uuid.UUID   `json:"id" db:"id"`
+	StreamID     uuid.UUID   `json:"stream_id" db:"stream_id"`
+	TrackID      string      `json:"track_id" db:"track_id"`
+	Status       TrackStatus `json:"status" db:"status"`
+	FirstSeen    time.Time   `json:"first_seen" db:"first_seen"`
+	LastSeen     time.Time   `json:"last_seen" db:"last_seen"`
+	DwellSeconds float64     `json:"dwell_seconds" db:"dwell_seconds"`
+	FrameCount   int         `json:"frame_count" db:"frame_count"`
+	PersonID     *uuid.UUID  `json:"person_id,omitempty" db:"person_id"` // consolidated identity
+	MatchScore   float32     `json:"match_score,omitempty" db:"match_score"`
+	// Gender and age smoothed over the track's recognition passes.
+	Gender           string    `json:"gender" db:"gender"`
+	GenderConfidence float32   `json:"gender_confidence" db:"gender_confidence"`
+	Age              int       `json:"age" db:"age"`
+	AgeRange         string    `json:"age_range" db:"age_range"`
+	AttributeSamples int       `json:"attribute_samples" db:"attribute_samples"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TrackEvent is published by the vision worker when a track starts, is
+// re-recognized, or ends.
+type TrackEvent struct {
+	Type         TrackEventType `json:"type"`
+	StreamID     uuid.UUID      `json:"stream_id"`
+	TrackID      string         `json:"track_id"`
+	FirstSeen    time.Time      `json:"first_seen"`
+	LastSeen     time.Time      `json:"last_seen"`
+	DwellSeconds float64        `json:"dwell_seconds"`
+	FrameCount   int            `json:"frame_count"`
+	PersonID     *uuid.UUID     `json:"person_id,omitempty"` // consolidated identity
+	MatchScore   float32        `json:"match_score,omitempty"`
+	// Gender and age smoothed over the track's recognition passes.
+	Gender           string  `json:"gender"`
+	GenderConfidence float32 `json:"gender_confidence"`
+	Age              int     `json:"age"`
+	AgeRange         string  `json:"age_range"`
+	AttributeSamples int     `json:"attribute_samples"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Visitor is an anonymous identity: a cluster of unknown tracks whose faces
+// look alike, found by the clustering job.
+type Visitor struct {
+	ID           uuid.UUID   `json:"id" db:"id"`
+	ModelVersion string      `json:"model_version" db:"model_version"`
+	TrackCount   int         `json:"track_count" db:"track_count"`
+	EventCount   int         `json:"event_count" db:"event_count"`
+	FirstSeen    time.Time   `json:"first_seen" db:"first_seen"`
+	LastSeen     time.Time   `json:"last_seen" db:"last_seen"`
+	PersonID     *uuid.UUID  `json:"person_id,omitempty" db:"person_id"` // set once promoted
+	SnapshotIDs  []uuid.UUID `json:"snapshot_event_ids"`                 // best events of distinct tracks
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// FootfallBucket counts the unique people seen in one time bucket. Tracks of
+// the same face are counted once, whether or not the face is known.
+type FootfallBucket struct {
+	Start          time.Time `json:"start"`
+	UniqueVisitors int       `json:"unique_visitors"` // known + unknown
+	Known          int       `json:"known"`           // distinct matched persons
+	Unknown        int       `json:"unknown"`         // distinct unmatched faces
+	Tracks         int       `json:"tracks"`
+}
+
+// ReturningVisitor is an anonymous visitor seen on several distinct days.
+type ReturningVisitor struct {
+	VisitorID uuid.UUID  `json:"visitor_id"`
+	PersonID  *uuid.UUID `json:"person_id,omitempty"` // set once promoted
+	Days      int        `json:"days"`
+	Tracks    int        `json:"tracks"`
+	FirstSeen time.Time  `json:"first_seen"`
+	LastSeen  time.Time  `json:"last_seen"`
+}
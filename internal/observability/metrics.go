@@ -24,6 +24,36 @@ var (
 		Help:      "Total number of faces recognized from database",
 	}, []string{"stream_id"})
 
+	SpoofsDetected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fd",
+		Name:      "spoofs_detected_total",
+		Help:      "Face crops scoring below the liveness threshold",
+	}, []string{"stream_id"})
+
+	LineCrossings = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fd",
+		Name:      "line_crossings_total",
+		Help:      "Track centroids crossing a stream tripwire",
+	}, []string{"stream_id", "line", "direction"})
+
+	ZoneEntries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fd",
+		Name:      "zone_entries_total",
+		Help:      "Tracks entering a stream zone",
+	}, []string{"stream_id", "zone"})
+
+	ZoneExits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fd",
+		Name:      "zone_exits_total",
+		Help:      "Tracks leaving a stream zone, including tracks that ended inside it",
+	}, []string{"stream_id", "zone"})
+
+	ZoneOccupancy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "fd",
+		Name:      "zone_occupancy",
+		Help:      "Tracks currently inside a stream zone",
+	}, []string{"stream_id", "zone"})
+
 	InferenceDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "fd",
 		Name:      "inference_duration_seconds",
@@ -31,6 +61,25 @@ var (
 		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 10),
 	}, []string{"stage"})
 
+	InferenceBatchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "fd",
+		Name:      "inference_batch_size",
+		Help:      "Faces per batched model run",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+	}, []string{"model"})
+
+	FacesReembedded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fd",
+		Name:      "faces_reembedded_total",
+		Help:      "Enrollment faces processed by the re-embedding job",
+	}, []string{"result"})
+
+	VisitorTracksClustered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "fd",
+		Name:      "visitor_tracks_clustered_total",
+		Help:      "Unknown tracks visited by the visitor clustering job",
+	}, []string{"result"})
+
 	QueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
 		Namespace: "fd",
 		Name:      "queue_depth",
@@ -55,4 +104,10 @@ var (
 		Name:      "ws_connections",
 		Help:      "Number of active WebSocket connections",
 	})
+
+	LiveViewers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "fd",
+		Name:      "live_viewers",
+		Help:      "Number of open live stream previews",
+	})
 )
@@ -2,8 +2,11 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -37,6 +40,8 @@ func NewConsumer(natsURL string) (*Consumer, error) {
 
 // ConsumeFrames starts consuming frame tasks from the FRAMES stream.
 // workerCount determines how many goroutines process messages concurrently.
+// Messages are not routed by stream; it must not share the FRAMES stream with
+// ConsumeFramesWithIndex, whose partition consumers would overlap its filter.
 func (c *Consumer) ConsumeFrames(ctx context.Context, consumerName string, handler MessageHandler, workerCount int) error {
 	stream, err := c.js.Stream(ctx, FramesStreamName)
 	if err != nil {
@@ -113,22 +118,23 @@ type WorkerHandler func(ctx context.Context, msg jetstream.Msg, workerID int) er
 
 // ConsumeFramesWithIndex is like ConsumeFrames but passes the worker index to the handler,
 // so each goroutine can use its own dedicated pipeline instance.
+//
+// Routing is sticky per stream so per-stream state (e.g. trackers) stays on one worker:
+//   - across processes, the FramePartitions partitions are spread over live worker
+//     processes with a consistent hash ring; each process only pulls the partitions
+//     it owns and rebalances when processes join or leave (see Membership);
+//   - within a process, frames are dispatched to goroutines by a consistent hash of
+//     the stream ID taken from the "frames.<partition>.<stream_id>" subject.
 func (c *Consumer) ConsumeFramesWithIndex(ctx context.Context, consumerName string, handler WorkerHandler, workerCount int) error {
 	stream, err := c.js.Stream(ctx, FramesStreamName)
 	if err != nil {
 		return fmt.Errorf("get stream %s: %w", FramesStreamName, err)
 	}
 
-	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
-		Name:          consumerName,
-		Durable:       consumerName,
-		AckPolicy:     jetstream.AckExplicitPolicy,
-		AckWait:       30 * time.Second,
-		MaxDeliver:    3,
-		FilterSubject: FramesSubjectBase + ".>",
-	})
-	if err != nil {
-		return fmt.Errorf("create consumer %s: %w", consumerName, err)
+	// The pre-partitioning catch-all consumer would overlap with the per-partition
+	// consumers, which a work-queue stream does not allow.
+	if err := stream.DeleteConsumer(ctx, consumerName); err != nil && !errors.Is(err, jetstream.ErrConsumerNotFound) {
+		slog.Warn("delete legacy frame consumer", "consumer", consumerName, "error", err)
 	}
 
 	// One channel per worker — each goroutine owns its channel exclusively
@@ -137,43 +143,99 @@ func (c *Consumer) ConsumeFramesWithIndex(ctx context.Context, consumerName stri
 		channels[i] = make(chan jetstream.Msg, 2)
 	}
 
-	// Fetch loop: round-robin dispatch to per-worker channels
+	localRing := NewHashRing(100)
+	workerNames := make([]string, workerCount)
+	workerIndex := make(map[string]int, workerCount)
+	for i := range workerNames {
+		workerNames[i] = strconv.Itoa(i)
+		workerIndex[workerNames[i]] = i
+	}
+	localRing.Set(workerNames)
+	dispatch := func(msg jetstream.Msg) int {
+		return workerIndex[localRing.Get(StreamIDFromSubject(msg.Subject()))]
+	}
+
+	membership := NewMembership(c.nc)
+	if err := membership.Start(ctx); err != nil {
+		return fmt.Errorf("start worker membership: %w", err)
+	}
+
+	// wg tracks the per-partition fetch loops so worker channels are closed only after
+	// every producer has stopped. Sends need no lock: a fetch loop blocked on one busy
+	// worker must not hold up the others.
+	var wg sync.WaitGroup
+
+	// Partition ownership loop: (re)assign partitions whenever membership changes.
 	go func() {
-		idx := 0
+		ring := NewHashRing(100)
+		owned := make(map[int]context.CancelFunc)
+
+		defer func() {
+			for _, stop := range owned {
+				stop()
+			}
+			wg.Wait()
+			for _, ch := range channels {
+				close(ch)
+			}
+		}()
+
 		for {
+			var members []string
 			select {
 			case <-ctx.Done():
-				for _, ch := range channels {
-					close(ch)
-				}
 				return
-			default:
+			case members = <-membership.Changes():
 			}
 
-			batch, err := cons.Fetch(workerCount, jetstream.FetchMaxWait(5*time.Second))
-			if err != nil {
-				if ctx.Err() != nil {
-					for _, ch := range channels {
-						close(ch)
+			ring.Set(members)
+			acquired, released := 0, 0
+			for p := 0; p < FramePartitions; p++ {
+				mine := ring.Get(strconv.Itoa(p)) == membership.ID()
+				stop, running := owned[p]
+				switch {
+				case mine && !running:
+					cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+						Name:          fmt.Sprintf("%s-p%02d", consumerName, p),
+						Durable:       fmt.Sprintf("%s-p%02d", consumerName, p),
+						AckPolicy:     jetstream.AckExplicitPolicy,
+						AckWait:       30 * time.Second,
+						MaxDeliver:    3,
+						FilterSubject: PartitionSubject(p),
+					})
+					if err != nil {
+						slog.Warn("create partition consumer", "partition", p, "error", err)
+						continue
 					}
-					return
+					pctx, cancel := context.WithCancel(ctx)
+					owned[p] = cancel
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						c.fetchPartition(pctx, cons, workerCount, func(msg jetstream.Msg) bool {
+							select {
+							case channels[dispatch(msg)] <- msg:
+								return true
+							case <-pctx.Done():
+								return false
+							}
+						})
+					}()
+					acquired++
+				case !mine && running:
+					stop()
+					delete(owned, p)
+					released++
 				}
-				slog.Warn("fetch frames error", "error", err)
-				time.Sleep(time.Second)
-				continue
 			}
 
-			for msg := range batch.Messages() {
-				select {
-				case channels[idx%workerCount] <- msg:
-					idx++
-				case <-ctx.Done():
-					for _, ch := range channels {
-						close(ch)
-					}
-					return
-				}
-			}
+			slog.Info("frame partitions rebalanced",
+				"member", membership.ID(),
+				"members", len(members),
+				"owned", len(owned),
+				"acquired", acquired,
+				"released", released,
+			)
 		}
 	}()
 
@@ -191,10 +253,37 @@ func (c *Consumer) ConsumeFramesWithIndex(ctx context.Context, consumerName stri
 		}(i, channels[i])
 	}
 
-	slog.Info("frame consumer started (indexed)", "consumer", consumerName, "workers", workerCount)
+	slog.Info("frame consumer started (sticky)", "consumer", consumerName, "workers", workerCount, "member", membership.ID())
 	return nil
 }
 
+// fetchPartition pulls batches from one partition consumer until ctx is cancelled,
+// handing each message to send. send returns false if the message could not be queued.
+func (c *Consumer) fetchPartition(ctx context.Context, cons jetstream.Consumer, batchSize int, send func(jetstream.Msg) bool) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		batch, err := cons.Fetch(batchSize, jetstream.FetchMaxWait(5*time.Second))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("fetch frames error", "error", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		for msg := range batch.Messages() {
+			if !send(msg) {
+				// Partition released mid-batch: let another owner redeliver it.
+				_ = msg.Nak()
+			}
+		}
+	}
+}
+
 // ConsumeEvents starts consuming detection events (for API to broadcast via WebSocket).
 func (c *Consumer) ConsumeEvents(ctx context.Context, consumerName string, handler MessageHandler) error {
 	stream, err := c.js.Stream(ctx, EventsStreamName)
@@ -250,3 +339,14 @@ func (c *Consumer) ConsumeEvents(ctx context.Context, consumerName string, handl
 func (c *Consumer) Close() {
 	c.nc.Close()
 }
+
+// SubscribeLive calls handler with each processed frame announced by PublishLive.
+func (c *Consumer) SubscribeLive(handler func(data []byte)) error {
+	_, err := c.nc.Subscribe(LiveSubjectBase+".>", func(msg *nats.Msg) {
+		handler(msg.Data)
+	})
+	if err != nil {
+		return fmt.Errorf("subscribe %s: %w", LiveSubjectBase, err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	workersHeartbeatSubject = "workers.heartbeat"
+	workersLeaveSubject     = "workers.leave"
+)
+
+// Membership tracks live worker processes through heartbeats on core NATS.
+// Every process announces itself periodically; members that miss heartbeats
+// for longer than ttl are dropped. Changes are delivered on Changes().
+type Membership struct {
+	nc       *nats.Conn
+	id       string
+	interval time.Duration
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	members map[string]time.Time
+	changes chan []string
+}
+
+type heartbeat struct {
+	ID string `json:"id"`
+}
+
+// NewMembership creates a membership tracker with a unique instance ID for this process.
+func NewMembership(nc *nats.Conn) *Membership {
+	host, _ := os.Hostname()
+	return &Membership{
+		nc:       nc,
+		id:       fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8]),
+		interval: 2 * time.Second,
+		ttl:      7 * time.Second,
+		members:  make(map[string]time.Time),
+		changes:  make(chan []string, 1),
+	}
+}
+
+// ID returns this process's member ID.
+func (m *Membership) ID() string {
+	return m.id
+}
+
+// Changes delivers the sorted member list whenever it changes.
+// Only the latest list is kept if the reader falls behind.
+func (m *Membership) Changes() <-chan []string {
+	return m.changes
+}
+
+// Start subscribes to heartbeats and begins announcing this process.
+// On ctx cancellation a leave message is sent so peers rebalance immediately.
+func (m *Membership) Start(ctx context.Context) error {
+	hbSub, err := m.nc.Subscribe(workersHeartbeatSubject, func(msg *nats.Msg) {
+		var hb heartbeat
+		if err := json.Unmarshal(msg.Data, &hb); err != nil || hb.ID == "" {
+			return
+		}
+		m.mu.Lock()
+		_, known := m.members[hb.ID]
+		m.members[hb.ID] = time.Now()
+		m.mu.Unlock()
+		if !known {
+			slog.Info("worker joined", "member", hb.ID)
+			m.notify()
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("subscribe %s: %w", workersHeartbeatSubject, err)
+	}
+
+	leaveSub, err := m.nc.Subscribe(workersLeaveSubject, func(msg *nats.Msg) {
+		var hb heartbeat
+		if err := json.Unmarshal(msg.Data, &hb); err != nil || hb.ID == "" || hb.ID == m.id {
+			return
+		}
+		m.mu.Lock()
+		_, known := m.members[hb.ID]
+		delete(m.members, hb.ID)
+		m.mu.Unlock()
+		if known {
+			slog.Info("worker left", "member", hb.ID)
+			m.notify()
+		}
+	})
+	if err != nil {
+		_ = hbSub.Unsubscribe()
+		return fmt.Errorf("subscribe %s: %w", workersLeaveSubject, err)
+	}
+
+	// Register ourselves immediately so a single process owns everything from the start.
+	m.mu.Lock()
+	m.members[m.id] = time.Now()
+	m.mu.Unlock()
+	m.notify()
+	m.publish(workersHeartbeatSubject)
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				m.publish(workersLeaveSubject)
+				_ = hbSub.Unsubscribe()
+				_ = leaveSub.Unsubscribe()
+				return
+			case <-ticker.C:
+				m.publish(workersHeartbeatSubject)
+				m.expire()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (m *Membership) publish(subject string) {
+	data, _ := json.Marshal(heartbeat{ID: m.id})
+	if err := m.nc.Publish(subject, data); err != nil {
+		slog.Warn("publish membership", "subject", subject, "error", err)
+	}
+}
+
+// expire drops members whose last heartbeat is older than ttl.
+func (m *Membership) expire() {
+	now := time.Now()
+	changed := false
+	m.mu.Lock()
+	for id, seen := range m.members {
+		if id != m.id && now.Sub(seen) > m.ttl {
+			delete(m.members, id)
+			slog.Info("worker expired", "member", id)
+			changed = true
+		}
+	}
+	m.members[m.id] = now
+	m.mu.Unlock()
+	if changed {
+		m.notify()
+	}
+}
+
+// notify pushes the current member list, replacing any undelivered one.
+// Holding mu while sending keeps concurrent notifications ordered.
+func (m *Membership) notify() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]string, 0, len(m.members))
+	for id := range m.members {
+		list = append(list, id)
+	}
+	sort.Strings(list)
+
+	for {
+		select {
+		case m.changes <- list:
+			return
+		default:
+		}
+		select {
+		case <-m.changes:
+		default:
+		}
+	}
+}
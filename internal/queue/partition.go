@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FramePartitions is the number of partitions frame subjects are spread across.
+// Frames are published to "frames.<partition>.<stream_id>"; all frames of one stream
+// share a partition, and partitions are the unit of ownership between worker processes.
+// Changing this value re-routes every stream, so keep it stable across deployments.
+const FramePartitions = 64
+
+// FramePartition returns the partition a stream's frames are published to.
+func FramePartition(streamID string) int {
+	return int(hashKey(streamID) % FramePartitions)
+}
+
+// FrameSubject returns the NATS subject for a stream's frame tasks.
+func FrameSubject(streamID string) string {
+	return fmt.Sprintf("%s.%d.%s", FramesSubjectBase, FramePartition(streamID), streamID)
+}
+
+// PartitionSubject returns the filter subject matching every stream in a partition.
+func PartitionSubject(partition int) string {
+	return fmt.Sprintf("%s.%d.>", FramesSubjectBase, partition)
+}
+
+// StreamIDFromSubject extracts the stream ID (last token) from a frame subject.
+func StreamIDFromSubject(subject string) string {
+	if i := strings.LastIndexByte(subject, '.'); i >= 0 {
+		return subject[i+1:]
+	}
+	return subject
+}
+
+// HashRing is a consistent hash ring: keys map to members such that adding or
+// removing a member only moves the keys that member owned (or gains).
+// It is not safe for concurrent use.
+type HashRing struct {
+	replicas int
+	points   []uint32
+	owners   map[uint32]string
+}
+
+// NewHashRing creates an empty ring with the given number of virtual nodes per member.
+func NewHashRing(replicas int) *HashRing {
+	if replicas <= 0 {
+		replicas = 100
+	}
+	return &HashRing{replicas: replicas, owners: make(map[uint32]string)}
+}
+
+// Set replaces the ring members.
+func (r *HashRing) Set(members []string) {
+	r.points = r.points[:0]
+	r.owners = make(map[uint32]string, len(members)*r.replicas)
+	for _, m := range members {
+		for i := 0; i < r.replicas; i++ {
+			h := hashKey(m + "#" + strconv.Itoa(i))
+			if _, taken := r.owners[h]; taken {
+				continue
+			}
+			r.owners[h] = m
+			r.points = append(r.points, h)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+}
+
+// Get returns the member owning key, or "" if the ring is empty.
+func (r *HashRing) Get(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+func hashKey(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
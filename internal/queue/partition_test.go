@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ringOwners(members []string, keys []string) map[string]string {
+	r := NewHashRing(100)
+	r.Set(members)
+	owners := make(map[string]string, len(keys))
+	for _, k := range keys {
+		owners[k] = r.Get(k)
+	}
+	return owners
+}
+
+func TestHashRingStable(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("stream-%d", i)
+	}
+	a := ringOwners([]string{"w1", "w2", "w3"}, keys)
+	b := ringOwners([]string{"w3", "w1", "w2"}, keys)
+	for _, k := range keys {
+		if a[k] != b[k] {
+			t.Fatalf("key %s: owner %s vs %s depending on member order", k, a[k], b[k])
+		}
+	}
+
+	if got := NewHashRing(10).Get("stream-1"); got != "" {
+		t.Errorf("empty ring: Get = %q, want \"\"", got)
+	}
+}
+
+func TestHashRingMembershipChange(t *testing.T) {
+	keys := make([]string, 10000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%08x-stream", i*7919)
+	}
+
+	tests := []struct {
+		name          string
+		before, after []string
+		maxMoved      float64 // share of keys
+	}{
+		{name: "member joins", before: []string{"w1", "w2", "w3"}, after: []string{"w1", "w2", "w3", "w4"}, maxMoved: 0.4},
+		{name: "member leaves", before: []string{"w1", "w2", "w3", "w4"}, after: []string{"w1", "w2", "w4"}, maxMoved: 0.4},
+		{name: "no change", before: []string{"w1", "w2"}, after: []string{"w1", "w2"}, maxMoved: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := ringOwners(tt.before, keys)
+			after := ringOwners(tt.after, keys)
+			inBefore := make(map[string]bool)
+			for _, m := range tt.before {
+				inBefore[m] = true
+			}
+			inAfter := make(map[string]bool)
+			for _, m := range tt.after {
+				inAfter[m] = true
+			}
+
+			moved := 0
+			for _, k := range keys {
+				if before[k] == after[k] {
+					continue
+				}
+				moved++
+				// Only keys of a leaving member, or keys taken by a joining one, move
+				if inAfter[before[k]] && inBefore[after[k]] {
+					t.Fatalf("key %s moved from %s to %s, both members before and after", k, before[k], after[k])
+				}
+			}
+			if share := float64(moved) / float64(len(keys)); share > tt.maxMoved {
+				t.Errorf("%.0f%% of keys moved, want at most %.0f%%", share*100, tt.maxMoved*100)
+			}
+		})
+	}
+}
+
+func TestHashRingSpread(t *testing.T) {
+	members := []string{"w1", "w2", "w3", "w4"}
+	r := NewHashRing(100)
+	r.Set(members)
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		counts[r.Get(fmt.Sprint(i))]++
+	}
+	for _, m := range members {
+		// An even share is 25%
+		if share := float64(counts[m]) / 10000; share < 0.1 || share > 0.4 {
+			t.Errorf("member %s owns %.0f%% of keys", m, share*100)
+		}
+	}
+}
+
+func TestFrameSubject(t *testing.T) {
+	const id = "3f1e6c2a-0000-4000-8000-000000000001"
+	subj := FrameSubject(id)
+	if got := StreamIDFromSubject(subj); got != id {
+		t.Errorf("StreamIDFromSubject(%q) = %q, want %q", subj, got, id)
+	}
+	if p := FramePartition(id); p < 0 || p >= FramePartitions {
+		t.Errorf("FramePartition = %d, want in [0, %d)", p, FramePartitions)
+	}
+	if FramePartition(id) != FramePartition(id) {
+		t.Error("FramePartition is not stable")
+	}
+}
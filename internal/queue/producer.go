@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -16,8 +17,27 @@ const (
 	FramesSubjectBase = "frames"
 	EventsStreamName  = "EVENTS"
 	EventsSubjectBase = "events"
+	// LiveSubjectBase prefixes the core NATS subjects of processed frames for
+	// live previews: "live.<stream_id>". Not persisted.
+	LiveSubjectBase = "live"
+
+	// trackEventsToken suffixes track lifecycle subjects: "events.<stream_id>.track".
+	trackEventsToken = "track"
+	// analyticsEventsToken suffixes tripwire/zone counter subjects: "events.<stream_id>.analytics".
+	analyticsEventsToken = "analytics"
 )
 
+// IsTrackEventSubject reports whether an EVENTS subject carries a track lifecycle event
+// rather than a per-face detection result.
+func IsTrackEventSubject(subject string) bool {
+	return strings.HasSuffix(subject, "."+trackEventsToken)
+}
+
+// IsAnalyticsEventSubject reports whether an EVENTS subject carries tripwire/zone counts.
+func IsAnalyticsEventSubject(subject string) bool {
+	return strings.HasSuffix(subject, "."+analyticsEventsToken)
+}
+
 type Producer struct {
 	nc *nats.Conn
 	js jetstream.JetStream
@@ -97,14 +117,14 @@ func (p *Producer) EnsureStreams(ctx context.Context) error {
 	return nil
 }
 
-// PublishFrame publishes a frame task to NATS.
+// PublishFrame publishes a frame task to NATS on the stream's partition subject.
 func (p *Producer) PublishFrame(ctx context.Context, streamID string, data interface{}) error {
 	payload, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("marshal frame task: %w", err)
 	}
 
-	subject := fmt.Sprintf("%s.%s", FramesSubjectBase, streamID)
+	subject := FrameSubject(streamID)
 	_, err = p.js.Publish(ctx, subject, payload)
 	if err != nil {
 		return fmt.Errorf("publish frame: %w", err)
@@ -127,6 +147,28 @@ func (p *Producer) PublishEvent(ctx context.Context, streamID string, data inter
 	return nil
 }
 
+// PublishTrackEvent publishes a track lifecycle event (started/updated/ended) to NATS.
+// It shares the EVENTS stream with detection events under "events.<stream_id>.track".
+func (p *Producer) PublishTrackEvent(ctx context.Context, streamID string, data interface{}) error {
+	return p.PublishEvent(ctx, streamID+"."+trackEventsToken, data)
+}
+
+// PublishAnalyticsEvent publishes changed tripwire/zone counters of a stream to NATS
+// under "events.<stream_id>.analytics".
+func (p *Producer) PublishAnalyticsEvent(ctx context.Context, streamID string, data interface{}) error {
+	return p.PublishEvent(ctx, streamID+"."+analyticsEventsToken, data)
+}
+
+// PublishLive announces a processed frame to live preview viewers via raw NATS
+// (not JetStream): a viewer only ever wants the latest frame.
+func (p *Producer) PublishLive(streamID string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal live frame: %w", err)
+	}
+	return p.nc.Publish(LiveSubjectBase+"."+streamID, payload)
+}
+
 // QueueDepth returns the number of pending messages in the FRAMES stream.
 func (p *Producer) QueueDepth(ctx context.Context) (uint64, error) {
 	stream, err := p.js.Stream(ctx, FramesStreamName)
@@ -0,0 +1,166 @@
+// Package redact obscures faces in frames and snapshots for privacy.
+package redact
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+const (
+	// padding widens each face box on every side (fraction of its size), so
+	// hair, ears and the chin are covered too.
+	padding = 0.15
+	// cells is the number of pixelation blocks (or blur radii) across a face's
+	// shorter side: coarse enough that the face cannot be recognized.
+	cells = 8
+	// jpegQuality is the quality redacted images are re-encoded with.
+	jpegQuality = 90
+)
+
+// Faces returns a copy of img with every box (x1, y1, x2, y2) obscured.
+func Faces(img image.Image, boxes [][4]float32, style models.RedactionStyle) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+
+	for _, box := range boxes {
+		w, h := box[2]-box[0], box[3]-box[1]
+		r := image.Rect(
+			int(box[0]-padding*w), int(box[1]-padding*h),
+			int(box[2]+padding*w)+1, int(box[3]+padding*h)+1,
+		).Intersect(b)
+		if r.Empty() {
+			continue
+		}
+		obscure(dst, r, style)
+	}
+	return dst
+}
+
+// JPEG decodes a JPEG image, obscures the boxes and re-encodes it.
+func JPEG(data []byte, boxes [][4]float32, style models.RedactionStyle) ([]byte, error) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode jpeg: %w", err)
+	}
+	return encode(Faces(img, boxes, style))
+}
+
+// WholeJPEG obscures an entire JPEG image, e.g. a face snapshot.
+func WholeJPEG(data []byte, style models.RedactionStyle) ([]byte, error) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode jpeg: %w", err)
+	}
+	return encode(Whole(img, style))
+}
+
+// Whole returns a copy of img obscured entirely.
+func Whole(img image.Image, style models.RedactionStyle) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+	obscure(dst, b, style)
+	return dst
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// obscure pixelates or blurs r of img in place.
+func obscure(img *image.RGBA, r image.Rectangle, style models.RedactionStyle) {
+	size := max(min(r.Dx(), r.Dy())/cells, 2)
+	if style == models.RedactionBlur {
+		// Three box blur passes approximate a Gaussian
+		for range 3 {
+			boxBlur(img, r, size, true)
+			boxBlur(img, r, size, false)
+		}
+		return
+	}
+	pixelate(img, r, size)
+}
+
+// pixelate fills each block of r with its mean colour.
+func pixelate(img *image.RGBA, r image.Rectangle, block int) {
+	for by := r.Min.Y; by < r.Max.Y; by += block {
+		for bx := r.Min.X; bx < r.Max.X; bx += block {
+			cell := image.Rect(bx, by, bx+block, by+block).Intersect(r)
+			var sum [4]int
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				off := img.PixOffset(cell.Min.X, y)
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					for c := range sum {
+						sum[c] += int(img.Pix[off+c])
+					}
+					off += 4
+				}
+			}
+			n := cell.Dx() * cell.Dy()
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				off := img.PixOffset(cell.Min.X, y)
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					for c := range sum {
+						img.Pix[off+c] = uint8(sum[c] / n)
+					}
+					off += 4
+				}
+			}
+		}
+	}
+}
+
+// boxBlur replaces each pixel of r with the mean of the pixels within radius
+// along its row (horizontal) or column. Samples outside r are clamped to its edge.
+func boxBlur(img *image.RGBA, r image.Rectangle, radius int, horizontal bool) {
+	lines, length, step := r.Dy(), r.Dx(), 4
+	if !horizontal {
+		lines, length, step = r.Dx(), r.Dy(), img.Stride
+	}
+	line := make([][4]int, length)
+	for l := 0; l < lines; l++ {
+		var start int
+		if horizontal {
+			start = img.PixOffset(r.Min.X, r.Min.Y+l)
+		} else {
+			start = img.PixOffset(r.Min.X+l, r.Min.Y)
+		}
+		for i := range line {
+			off := start + i*step
+			for c := 0; c < 4; c++ {
+				line[i][c] = int(img.Pix[off+c])
+			}
+		}
+
+		// Running sum over the window [i-radius, i+radius]
+		var sum [4]int
+		at := func(i int) [4]int { return line[min(max(i, 0), length-1)] }
+		for i := -radius; i <= radius; i++ {
+			p := at(i)
+			for c := range sum {
+				sum[c] += p[c]
+			}
+		}
+		n := 2*radius + 1
+		for i := 0; i < length; i++ {
+			off := start + i*step
+			for c := range sum {
+				img.Pix[off+c] = uint8(sum[c] / n)
+			}
+			out, in := at(i-radius), at(i+radius+1)
+			for c := range sum {
+				sum[c] += in[c] - out[c]
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+-- Track lifecycle: one row per tracked face, updated on start/update/end events
+CREATE TABLE IF NOT EXISTS tracks (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    stream_id     UUID NOT NULL REFERENCES streams(id) ON DELETE CASCADE,
+    track_id      VARCHAR(100) NOT NULL,
+    status        VARCHAR(20) NOT NULL DEFAULT 'active', -- active, ended
+    first_seen    TIMESTAMPTZ NOT NULL,
+    last_seen     TIMESTAMPTZ NOT NULL,
+    dwell_seconds REAL NOT NULL DEFAULT 0.0,
+    frame_count   INT NOT NULL DEFAULT 0,
+    person_id     UUID REFERENCES persons(id) ON DELETE SET NULL, -- best identity seen
+    match_score   REAL DEFAULT 0.0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (stream_id, track_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tracks_stream_first_seen ON tracks(stream_id, first_seen DESC);
+CREATE INDEX IF NOT EXISTS idx_tracks_person ON tracks(person_id) WHERE person_id IS NOT NULL;
+
+CREATE TRIGGER trg_tracks_updated_at
+    BEFORE UPDATE ON tracks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
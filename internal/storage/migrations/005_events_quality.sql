@@ -0,0 +1,2 @@
+-- Best-shot selection: quality score of the crop each event was computed from
+ALTER TABLE events ADD COLUMN IF NOT EXISTS quality_score REAL DEFAULT 0.0;
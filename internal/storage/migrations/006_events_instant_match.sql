@@ -0,0 +1,4 @@
+-- Identity voting: matched_person_id now holds the track's consolidated identity,
+-- the instant_* columns keep the raw top match of each recognition pass
+ALTER TABLE events ADD COLUMN IF NOT EXISTS instant_person_id UUID REFERENCES persons(id) ON DELETE SET NULL;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS instant_score REAL DEFAULT 0.0;
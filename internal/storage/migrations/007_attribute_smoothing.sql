@@ -0,0 +1,8 @@
+-- Gender/age are smoothed per track; record how many predictions they are based on
+ALTER TABLE events ADD COLUMN IF NOT EXISTS attribute_samples INT DEFAULT 0;
+
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS gender            VARCHAR(10) DEFAULT '';
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS gender_confidence REAL DEFAULT 0.0;
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS age               INT DEFAULT 0;
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS age_range         VARCHAR(20) DEFAULT '';
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS attribute_samples INT DEFAULT 0;
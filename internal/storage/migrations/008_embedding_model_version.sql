@@ -0,0 +1,7 @@
+-- Record which embedding model produced each vector; vectors of different models are not comparable.
+-- Existing rows were produced by the original InsightFace w600k_r50 embedder.
+ALTER TABLE face_embeddings ADD COLUMN IF NOT EXISTS model_version VARCHAR(100) NOT NULL DEFAULT 'w600k_r50';
+ALTER TABLE events          ADD COLUMN IF NOT EXISTS model_version VARCHAR(100) NOT NULL DEFAULT 'w600k_r50';
+
+-- Lets the re-embedding job find enrollment faces of other versions
+CREATE INDEX IF NOT EXISTS idx_face_embeddings_model_version ON face_embeddings(model_version);
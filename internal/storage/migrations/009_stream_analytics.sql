@@ -0,0 +1,12 @@
+-- Tripwire and zone counts per stream, one row per counter and minute
+CREATE TABLE IF NOT EXISTS stream_analytics (
+    stream_id      UUID NOT NULL REFERENCES streams(id) ON DELETE CASCADE,
+    bucket         TIMESTAMPTZ NOT NULL,  -- start of the minute
+    kind           VARCHAR(10) NOT NULL,  -- line, zone
+    name           VARCHAR(100) NOT NULL,
+    in_count       INT NOT NULL DEFAULT 0, -- line: crossings in; zone: entries
+    out_count      INT NOT NULL DEFAULT 0, -- line: crossings out; zone: exits
+    occupancy      INT NOT NULL DEFAULT 0, -- zone: at the last change in the bucket
+    peak_occupancy INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (stream_id, bucket, kind, name)
+);
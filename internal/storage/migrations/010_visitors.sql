@@ -0,0 +1,26 @@
+-- Anonymous visitors: clusters of unknown tracks built by the API's clustering job
+CREATE TABLE IF NOT EXISTS visitors (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    model_version VARCHAR(100) NOT NULL, -- embedder of the clustered embeddings
+    track_count   INT NOT NULL DEFAULT 0,
+    event_count   INT NOT NULL DEFAULT 0,
+    first_seen    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    person_id     UUID REFERENCES persons(id) ON DELETE SET NULL, -- set when promoted
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_visitors_last_seen ON visitors(last_seen DESC);
+
+CREATE TRIGGER trg_visitors_updated_at
+    BEFORE UPDATE ON visitors
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- clustered marks unknown events the job has visited, whether or not they joined a visitor
+ALTER TABLE events ADD COLUMN IF NOT EXISTS visitor_id UUID REFERENCES visitors(id) ON DELETE SET NULL;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS clustered BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_events_visitor ON events(visitor_id) WHERE visitor_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_events_unclustered ON events(timestamp)
+    WHERE NOT clustered AND matched_person_id IS NULL AND embedding IS NOT NULL;
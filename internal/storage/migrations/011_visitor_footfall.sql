@@ -0,0 +1,11 @@
+-- Cached unique-visitor counts of closed time buckets (see clustering.Footfall)
+CREATE TABLE IF NOT EXISTS visitor_footfall (
+    cache_key       VARCHAR(200) NOT NULL, -- scope, bucket unit, time zone and dedup distance
+    bucket          TIMESTAMPTZ NOT NULL,
+    unique_visitors INT NOT NULL,
+    known           INT NOT NULL,
+    unknown         INT NOT NULL,
+    tracks          INT NOT NULL,
+    computed_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (cache_key, bucket)
+);
@@ -0,0 +1,5 @@
+-- Liveness (anti-spoofing) result of each event's crop, for streams that check it
+ALTER TABLE events ADD COLUMN IF NOT EXISTS liveness_score REAL;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS spoof BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_events_spoof ON events (stream_id, timestamp DESC) WHERE spoof;
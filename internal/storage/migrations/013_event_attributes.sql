@@ -0,0 +1,5 @@
+-- Labels of the face attribute classifiers (mask, eyewear, hat, ...) per event:
+-- {"mask": {"label": "mask", "confidence": 0.97, "occluding": true}, ...}
+ALTER TABLE events ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_events_attributes ON events USING GIN (attributes jsonb_path_ops);
@@ -0,0 +1,4 @@
+-- Head pose (degrees) of each event's crop, solved from its landmarks
+ALTER TABLE events ADD COLUMN IF NOT EXISTS yaw REAL;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS pitch REAL;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS roll REAL;
@@ -0,0 +1,3 @@
+-- Face box of each event (x1, y1, x2, y2 in frame pixels), so its frame can be
+-- redacted on the fly while matched persons stay visible
+ALTER TABLE events ADD COLUMN IF NOT EXISTS bbox REAL[];
@@ -0,0 +1,3 @@
+-- Face landmarks of each event (x, y of the left eye, right eye, nose tip, left
+-- and right mouth corner in frame pixels), for annotated frames
+ALTER TABLE events ADD COLUMN IF NOT EXISTS landmarks REAL[];
@@ -0,0 +1,5 @@
+-- jsonb_path_ops only serves containment (@>); the attribute analytics look up
+-- events by classifier name with the key-exists operator (?), which needs the
+-- default jsonb_ops
+DROP INDEX IF EXISTS idx_events_attributes;
+CREATE INDEX IF NOT EXISTS idx_events_attributes ON events USING GIN (attributes);
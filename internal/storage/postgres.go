@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -171,18 +172,19 @@ func (s *PostgresStore) CountFaces(ctx context.Context, personID uuid.UUID) (int
 
 // --- Face Embeddings ---
 
-func (s *PostgresStore) AddFaceEmbedding(ctx context.Context, personID uuid.UUID, embedding []float32, quality float32, sourceKey string) (*models.FaceEmbedding, error) {
+func (s *PostgresStore) AddFaceEmbedding(ctx context.Context, personID uuid.UUID, embedding []float32, modelVersion string, quality float32, sourceKey string) (*models.FaceEmbedding, error) {
 	fe := &models.FaceEmbedding{
-		ID:        uuid.New(),
-		PersonID:  personID,
-		Embedding: embedding,
-		Quality:   quality,
-		SourceKey: sourceKey,
+		ID:           uuid.New(),
+		PersonID:     personID,
+		Embedding:    embedding,
+		ModelVersion: modelVersion,
+		Quality:      quality,
+		SourceKey:    sourceKey,
 	}
 	vec := pgvector.NewVector(embedding)
 	err := s.pool.QueryRow(ctx,
-		`INSERT INTO face_embeddings (id, person_id, embedding, quality, source_key) VALUES ($1, $2, $3, $4, $5) RETURNING created_at`,
-		fe.ID, fe.PersonID, vec, fe.Quality, fe.SourceKey,
+		`INSERT INTO face_embeddings (id, person_id, embedding, model_version, quality, source_key) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at`,
+		fe.ID, fe.PersonID, vec, fe.ModelVersion, fe.Quality, fe.SourceKey,
 	).Scan(&fe.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("add face embedding: %w", err)
@@ -204,7 +206,7 @@ func (s *PostgresStore) DeleteFaceEmbedding(ctx context.Context, personID, faceI
 
 func (s *PostgresStore) ListFaceEmbeddings(ctx context.Context, personID uuid.UUID) ([]models.FaceEmbedding, error) {
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, person_id, quality, source_key, created_at FROM face_embeddings WHERE person_id = $1 ORDER BY created_at DESC`,
+		`SELECT id, person_id, model_version, quality, source_key, created_at FROM face_embeddings WHERE person_id = $1 ORDER BY created_at DESC`,
 		personID)
 	if err != nil {
 		return nil, fmt.Errorf("list face embeddings: %w", err)
@@ -214,7 +216,7 @@ func (s *PostgresStore) ListFaceEmbeddings(ctx context.Context, personID uuid.UU
 	var faces []models.FaceEmbedding
 	for rows.Next() {
 		var fe models.FaceEmbedding
-		if err := rows.Scan(&fe.ID, &fe.PersonID, &fe.Quality, &fe.SourceKey, &fe.CreatedAt); err != nil {
+		if err := rows.Scan(&fe.ID, &fe.PersonID, &fe.ModelVersion, &fe.Quality, &fe.SourceKey, &fe.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan face embedding: %w", err)
 		}
 		faces = append(faces, fe)
@@ -222,8 +224,55 @@ func (s *PostgresStore) ListFaceEmbeddings(ctx context.Context, personID uuid.UU
 	return faces, nil
 }
 
+// ListStaleFaceEmbeddings returns up to limit enrollment faces whose embedding
+// was not produced by modelVersion, ordered by id and starting after afterID
+// (uuid.Nil for the first page). Embedding is not loaded.
+func (s *PostgresStore) ListStaleFaceEmbeddings(ctx context.Context, modelVersion string, afterID uuid.UUID, limit int) ([]models.FaceEmbedding, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT id, person_id, model_version, quality, source_key, created_at FROM face_embeddings
+		 WHERE model_version <> $1 AND id > $2
+		 ORDER BY id LIMIT $3`,
+		modelVersion, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list stale face embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var faces []models.FaceEmbedding
+	for rows.Next() {
+		var fe models.FaceEmbedding
+		if err := rows.Scan(&fe.ID, &fe.PersonID, &fe.ModelVersion, &fe.Quality, &fe.SourceKey, &fe.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan face embedding: %w", err)
+		}
+		faces = append(faces, fe)
+	}
+	return faces, rows.Err()
+}
+
+// CountStaleFaceEmbeddings counts enrollment faces not embedded with modelVersion.
+func (s *PostgresStore) CountStaleFaceEmbeddings(ctx context.Context, modelVersion string) (int, error) {
+	var count int
+	err := s.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM face_embeddings WHERE model_version <> $1`, modelVersion,
+	).Scan(&count)
+	return count, err
+}
+
+// UpdateFaceEmbedding replaces the embedding of an enrollment face with one
+// produced by modelVersion.
+func (s *PostgresStore) UpdateFaceEmbedding(ctx context.Context, faceID uuid.UUID, embedding []float32, modelVersion string, quality float32) error {
+	_, err := s.pool.Exec(ctx,
+		`UPDATE face_embeddings SET embedding = $2, model_version = $3, quality = $4 WHERE id = $1`,
+		faceID, pgvector.NewVector(embedding), modelVersion, quality)
+	if err != nil {
+		return fmt.Errorf("update face embedding: %w", err)
+	}
+	return nil
+}
+
 // SearchFaces finds the closest matching persons for a given embedding.
-func (s *PostgresStore) SearchFaces(ctx context.Context, embedding []float32, collectionID *uuid.UUID, threshold float64, limit int) ([]SearchMatch, error) {
+// Only enrollment faces embedded by modelVersion are compared.
+func (s *PostgresStore) SearchFaces(ctx context.Context, embedding []float32, modelVersion string, collectionID *uuid.UUID, threshold float64, limit int) ([]SearchMatch, error) {
 	if limit <= 0 {
 		limit = 5
 	}
@@ -238,19 +287,21 @@ func (s *PostgresStore) SearchFaces(ctx context.Context, embedding []float32, co
 			FROM face_embeddings fe
 			JOIN persons p ON p.id = fe.person_id
 			WHERE p.collection_id = $2
+			  AND fe.model_version = $5
 			  AND 1 - (fe.embedding <=> $1) >= $3
 			ORDER BY fe.embedding <=> $1
 			LIMIT $4`
-		args = []interface{}{vec, *collectionID, threshold, limit}
+		args = []interface{}{vec, *collectionID, threshold, limit, modelVersion}
 	} else {
 		query = `
 			SELECT fe.person_id, p.name, 1 - (fe.embedding <=> $1) AS score
 			FROM face_embeddings fe
 			JOIN persons p ON p.id = fe.person_id
-			WHERE 1 - (fe.embedding <=> $1) >= $2
+			WHERE fe.model_version = $4
+			  AND 1 - (fe.embedding <=> $1) >= $2
 			ORDER BY fe.embedding <=> $1
 			LIMIT $3`
-		args = []interface{}{vec, threshold, limit}
+		args = []interface{}{vec, threshold, limit, modelVersion}
 	}
 
 	rows, err := s.pool.Query(ctx, query, args...)
@@ -290,8 +341,9 @@ type EventMatch struct {
 }
 
 // SearchEvents finds detection events whose stored embedding is similar to the query embedding.
-// Optionally filtered by stream_id. Returns up to limit results above threshold.
-func (s *PostgresStore) SearchEvents(ctx context.Context, embedding []float32, streamID *uuid.UUID, threshold float64, limit int) ([]EventMatch, error) {
+// Only events embedded by modelVersion are compared. Optionally filtered by
+// stream_id. Returns up to limit results above threshold.
+func (s *PostgresStore) SearchEvents(ctx context.Context, embedding []float32, modelVersion string, streamID *uuid.UUID, threshold float64, limit int) ([]EventMatch, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -307,20 +359,22 @@ func (s *PostgresStore) SearchEvents(ctx context.Context, embedding []float32, s
 			FROM events
 			WHERE embedding IS NOT NULL
 			  AND stream_id = $2
+			  AND model_version = $5
 			  AND 1 - (embedding <=> $1) >= $3
 			ORDER BY embedding <=> $1
 			LIMIT $4`
-		args = []interface{}{vec, *streamID, threshold, limit}
+		args = []interface{}{vec, *streamID, threshold, limit, modelVersion}
 	} else {
 		query = `
 			SELECT id, stream_id, timestamp, 1 - (embedding <=> $1) AS score,
 			       gender, age, age_range, matched_person_id, snapshot_key
 			FROM events
 			WHERE embedding IS NOT NULL
+			  AND model_version = $4
 			  AND 1 - (embedding <=> $1) >= $2
 			ORDER BY embedding <=> $1
 			LIMIT $3`
-		args = []interface{}{vec, threshold, limit}
+		args = []interface{}{vec, threshold, limit, modelVersion}
 	}
 
 	rows, err := s.pool.Query(ctx, query, args...)
@@ -400,6 +454,19 @@ func (s *PostgresStore) UpdateStreamStatus(ctx context.Context, id uuid.UUID, st
 	return err
 }
 
+func (s *PostgresStore) UpdateStreamConfig(ctx context.Context, id uuid.UUID, config json.RawMessage) error {
+	tag, err := s.pool.Exec(ctx,
+		`UPDATE streams SET config = $1 WHERE id = $2`,
+		config, id)
+	if err != nil {
+		return fmt.Errorf("update stream config: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("stream not found")
+	}
+	return nil
+}
+
 func (s *PostgresStore) DeleteStream(ctx context.Context, id uuid.UUID) error {
 	tag, err := s.pool.Exec(ctx, `DELETE FROM streams WHERE id = $1`, id)
 	if err != nil {
@@ -421,16 +488,60 @@ func (s *PostgresStore) CreateEvent(ctx context.Context, ev *models.Event) error
 		v := pgvector.NewVector(ev.Embedding)
 		vec = &v
 	}
+	attrs := ev.Attributes
+	if attrs == nil {
+		attrs = models.FaceAttributes{}
+	}
+	var yaw, pitch, roll *float32
+	if ev.Pose != nil {
+		yaw, pitch, roll = &ev.Pose.Yaw, &ev.Pose.Pitch, &ev.Pose.Roll
+	}
 	_, err := s.pool.Exec(ctx,
-		`INSERT INTO events (id, stream_id, track_id, timestamp, gender, gender_confidence, age, age_range, confidence, embedding, matched_person_id, match_score, snapshot_key, frame_key, created_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
-		ev.ID, ev.StreamID, ev.TrackID, ev.Timestamp,
-		ev.Gender, ev.GenderConfidence, ev.Age, ev.AgeRange, ev.Confidence,
-		vec, ev.MatchedPersonID, ev.MatchScore, ev.SnapshotKey, ev.FrameKey, ev.CreatedAt)
+		`INSERT INTO events (id, stream_id, track_id, timestamp, bbox, landmarks, gender, gender_confidence, age, age_range, attribute_samples, confidence, embedding, model_version, matched_person_id, match_score, instant_person_id, instant_score, snapshot_key, frame_key, quality_score, liveness_score, spoof, attributes, yaw, pitch, roll, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28)`,
+		ev.ID, ev.StreamID, ev.TrackID, ev.Timestamp, ev.BBox, ev.Landmarks,
+		ev.Gender, ev.GenderConfidence, ev.Age, ev.AgeRange, ev.AttributeSamples, ev.Confidence,
+		vec, ev.ModelVersion, ev.MatchedPersonID, ev.MatchScore, ev.InstantPersonID, ev.InstantScore, ev.SnapshotKey, ev.FrameKey, ev.QualityScore,
+		ev.LivenessScore, ev.Spoof, attrs, yaw, pitch, roll, ev.CreatedAt)
 	return err
 }
 
-func (s *PostgresStore) QueryEvents(ctx context.Context, streamID uuid.UUID, from, to *time.Time, personID *uuid.UUID, unknown *bool, limit, offset int) ([]models.Event, int, error) {
+// SetTrackSnapshot points every event of a track at its new best-shot snapshot.
+// Returns the superseded snapshot keys nothing refers to any more (enrolled
+// faces keep theirs), for deleting from MinIO.
+func (s *PostgresStore) SetTrackSnapshot(ctx context.Context, streamID uuid.UUID, trackID, snapshotKey string) ([]string, error) {
+	rows, err := s.pool.Query(ctx,
+		`WITH old AS (
+		     SELECT DISTINCT snapshot_key FROM events
+		     WHERE stream_id = $1 AND track_id = $2 AND snapshot_key <> '' AND snapshot_key <> $3
+		 ), upd AS (
+		     UPDATE events SET snapshot_key = $3
+		     WHERE stream_id = $1 AND track_id = $2 AND snapshot_key IS DISTINCT FROM $3
+		 )
+		 SELECT snapshot_key FROM old
+		 WHERE NOT EXISTS (SELECT 1 FROM face_embeddings f WHERE f.source_key = old.snapshot_key)`,
+		streamID, trackID, snapshotKey)
+	if err != nil {
+		return nil, fmt.Errorf("set track snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	var superseded []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("scan superseded snapshot: %w", err)
+		}
+		superseded = append(superseded, key)
+	}
+	return superseded, rows.Err()
+}
+
+// QueryEvents lists a stream's events, newest first. attributes filters on face
+// attribute labels (classifier name → label); all must match. maxYaw and
+// maxPitch bound the absolute head pose angles in degrees, leaving out events
+// stored without a pose.
+func (s *PostgresStore) QueryEvents(ctx context.Context, streamID uuid.UUID, from, to *time.Time, personID *uuid.UUID, unknown *bool, attributes map[string]string, maxYaw, maxPitch *float32, limit, offset int) ([]models.Event, int, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -460,6 +571,25 @@ func (s *PostgresStore) QueryEvents(ctx context.Context, streamID uuid.UUID, fro
 	if unknown != nil && *unknown {
 		baseWhere += " AND matched_person_id IS NULL"
 	}
+	if len(attributes) > 0 {
+		filter := make(map[string]map[string]string, len(attributes))
+		for name, label := range attributes {
+			filter[name] = map[string]string{"label": label}
+		}
+		baseWhere += fmt.Sprintf(" AND attributes @> $%d", argIdx)
+		args = append(args, filter)
+		argIdx++
+	}
+	if maxYaw != nil {
+		baseWhere += fmt.Sprintf(" AND abs(yaw) <= $%d", argIdx)
+		args = append(args, *maxYaw)
+		argIdx++
+	}
+	if maxPitch != nil {
+		baseWhere += fmt.Sprintf(" AND abs(pitch) <= $%d", argIdx)
+		args = append(args, *maxPitch)
+		argIdx++
+	}
 
 	// Count total
 	var total int
@@ -470,7 +600,7 @@ func (s *PostgresStore) QueryEvents(ctx context.Context, streamID uuid.UUID, fro
 
 	// Fetch page
 	query := fmt.Sprintf(
-		`SELECT id, stream_id, track_id, timestamp, gender, gender_confidence, age, age_range, confidence, matched_person_id, match_score, snapshot_key, frame_key, created_at
+		`SELECT id, stream_id, track_id, timestamp, bbox, landmarks, gender, gender_confidence, age, age_range, attribute_samples, confidence, model_version, matched_person_id, match_score, instant_person_id, instant_score, snapshot_key, frame_key, quality_score, visitor_id, liveness_score, spoof, attributes, yaw, pitch, roll, created_at
 		 FROM events %s ORDER BY timestamp DESC LIMIT $%d OFFSET $%d`,
 		baseWhere, argIdx, argIdx+1)
 	args = append(args, limit, offset)
@@ -484,45 +614,821 @@ func (s *PostgresStore) QueryEvents(ctx context.Context, streamID uuid.UUID, fro
 	var events []models.Event
 	for rows.Next() {
 		var ev models.Event
-		if err := rows.Scan(&ev.ID, &ev.StreamID, &ev.TrackID, &ev.Timestamp,
-			&ev.Gender, &ev.GenderConfidence, &ev.Age, &ev.AgeRange, &ev.Confidence,
-			&ev.MatchedPersonID, &ev.MatchScore, &ev.SnapshotKey, &ev.FrameKey, &ev.CreatedAt); err != nil {
+		var yaw, pitch, roll *float32
+		if err := rows.Scan(&ev.ID, &ev.StreamID, &ev.TrackID, &ev.Timestamp, &ev.BBox, &ev.Landmarks,
+			&ev.Gender, &ev.GenderConfidence, &ev.Age, &ev.AgeRange, &ev.AttributeSamples, &ev.Confidence, &ev.ModelVersion,
+			&ev.MatchedPersonID, &ev.MatchScore, &ev.InstantPersonID, &ev.InstantScore, &ev.SnapshotKey, &ev.FrameKey, &ev.QualityScore, &ev.VisitorID,
+			&ev.LivenessScore, &ev.Spoof, &ev.Attributes, &yaw, &pitch, &roll, &ev.CreatedAt); err != nil {
 			return nil, 0, fmt.Errorf("scan event: %w", err)
 		}
+		ev.Pose = headPose(yaw, pitch, roll)
 		events = append(events, ev)
 	}
 	return events, total, nil
 }
 
-// GetEmbeddingByTrackID returns the embedding of the highest-confidence event for a given track_id.
-func (s *PostgresStore) GetEmbeddingByTrackID(ctx context.Context, streamID uuid.UUID, trackID string) ([]float32, error) {
+// GetEmbeddingByTrackID returns the embedding of the track's best-quality event
+// (ties broken by detector confidence) and the model version that produced it.
+func (s *PostgresStore) GetEmbeddingByTrackID(ctx context.Context, streamID uuid.UUID, trackID string) ([]float32, string, error) {
 	var vec pgvector.Vector
+	var modelVersion string
 	err := s.pool.QueryRow(ctx,
-		`SELECT embedding FROM events
+		`SELECT embedding, model_version FROM events
 		 WHERE stream_id = $1 AND track_id = $2 AND embedding IS NOT NULL
-		 ORDER BY confidence DESC LIMIT 1`,
+		 ORDER BY quality_score DESC, confidence DESC LIMIT 1`,
 		streamID, trackID,
-	).Scan(&vec)
+	).Scan(&vec, &modelVersion)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, nil
+			return nil, "", nil
 		}
-		return nil, fmt.Errorf("get embedding by track: %w", err)
+		return nil, "", fmt.Errorf("get embedding by track: %w", err)
 	}
-	return vec.Slice(), nil
+	return vec.Slice(), modelVersion, nil
 }
 
 // GetEvent returns a single event by ID.
 func (s *PostgresStore) GetEvent(ctx context.Context, id uuid.UUID) (*models.Event, error) {
 	var ev models.Event
+	var yaw, pitch, roll *float32
 	err := s.pool.QueryRow(ctx,
-		`SELECT id, stream_id, track_id, timestamp, gender, gender_confidence, age, age_range, confidence, matched_person_id, match_score, snapshot_key, frame_key, created_at
+		`SELECT id, stream_id, track_id, timestamp, bbox, landmarks, gender, gender_confidence, age, age_range, attribute_samples, confidence, model_version, matched_person_id, match_score, instant_person_id, instant_score, snapshot_key, frame_key, quality_score, visitor_id, liveness_score, spoof, attributes, yaw, pitch, roll, created_at
 		 FROM events WHERE id = $1`, id).
-		Scan(&ev.ID, &ev.StreamID, &ev.TrackID, &ev.Timestamp,
-			&ev.Gender, &ev.GenderConfidence, &ev.Age, &ev.AgeRange, &ev.Confidence,
-			&ev.MatchedPersonID, &ev.MatchScore, &ev.SnapshotKey, &ev.FrameKey, &ev.CreatedAt)
+		Scan(&ev.ID, &ev.StreamID, &ev.TrackID, &ev.Timestamp, &ev.BBox, &ev.Landmarks,
+			&ev.Gender, &ev.GenderConfidence, &ev.Age, &ev.AgeRange, &ev.AttributeSamples, &ev.Confidence, &ev.ModelVersion,
+			&ev.MatchedPersonID, &ev.MatchScore, &ev.InstantPersonID, &ev.InstantScore, &ev.SnapshotKey, &ev.FrameKey, &ev.QualityScore, &ev.VisitorID,
+			&ev.LivenessScore, &ev.Spoof, &ev.Attributes, &yaw, &pitch, &roll, &ev.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get event: %w", err)
 	}
+	ev.Pose = headPose(yaw, pitch, roll)
 	return &ev, nil
 }
+
+// FrameFace is a face reported by an event of a stored frame.
+type FrameFace struct {
+	TrackID    string
+	BBox       [4]float32
+	Landmarks  []float32 // nil for events stored without landmarks
+	PersonID   *uuid.UUID
+	PersonName string
+	MatchScore float32
+	Gender     string
+	Age        int
+}
+
+// ListFrameFaces returns the faces of a frame's events, those stored without a
+// box left out.
+func (s *PostgresStore) ListFrameFaces(ctx context.Context, frameKey string) ([]FrameFace, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT e.track_id, e.bbox, e.landmarks, e.matched_person_id, COALESCE(p.name, ''), e.match_score, e.gender, e.age
+		 FROM events e LEFT JOIN persons p ON p.id = e.matched_person_id
+		 WHERE e.frame_key = $1 AND cardinality(e.bbox) = 4
+		 ORDER BY e.timestamp`,
+		frameKey)
+	if err != nil {
+		return nil, fmt.Errorf("query frame faces: %w", err)
+	}
+	defer rows.Close()
+
+	var faces []FrameFace
+	for rows.Next() {
+		var f FrameFace
+		var bbox []float32
+		if err := rows.Scan(&f.TrackID, &bbox, &f.Landmarks, &f.PersonID, &f.PersonName, &f.MatchScore, &f.Gender, &f.Age); err != nil {
+			return nil, fmt.Errorf("scan frame face: %w", err)
+		}
+		f.BBox = [4]float32(bbox)
+		faces = append(faces, f)
+	}
+	return faces, rows.Err()
+}
+
+// LatestFrameKey returns the frame of a stream's most recent event, "" if none.
+func (s *PostgresStore) LatestFrameKey(ctx context.Context, streamID uuid.UUID) (string, error) {
+	var key string
+	err := s.pool.QueryRow(ctx,
+		`SELECT frame_key FROM events
+		 WHERE stream_id = $1 AND frame_key != ''
+		 ORDER BY timestamp DESC LIMIT 1`,
+		streamID).Scan(&key)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("latest frame key: %w", err)
+	}
+	return key, nil
+}
+
+// headPose assembles an event's pose columns, nil if it was stored without one.
+func headPose(yaw, pitch, roll *float32) *models.HeadPose {
+	if yaw == nil || pitch == nil || roll == nil {
+		return nil
+	}
+	return &models.HeadPose{Yaw: *yaw, Pitch: *pitch, Roll: *roll}
+}
+
+// --- Tracks ---
+
+const trackColumns = `id, stream_id, track_id, status, first_seen, last_seen, dwell_seconds, frame_count, person_id, match_score,
+	gender, gender_confidence, age, age_range, attribute_samples, created_at, updated_at`
+
+// trackScanDest returns scan destinations matching trackColumns.
+func trackScanDest(t *models.Track) []interface{} {
+	return []interface{}{&t.ID, &t.StreamID, &t.TrackID, &t.Status, &t.FirstSeen, &t.LastSeen,
+		&t.DwellSeconds, &t.FrameCount, &t.PersonID, &t.MatchScore,
+		&t.Gender, &t.GenderConfidence, &t.Age, &t.AgeRange, &t.AttributeSamples, &t.CreatedAt, &t.UpdatedAt}
+}
+
+// UpsertTrack records a track lifecycle event. Events may arrive out of order,
+// so an ended track stays ended (keeping its final identity) and last_seen
+// never moves backwards.
+// Returns the resulting row.
+func (s *PostgresStore) UpsertTrack(ctx context.Context, ev *models.TrackEvent) (*models.Track, error) {
+	status := models.TrackStatusActive
+	if ev.Type == models.TrackEnded {
+		status = models.TrackStatusEnded
+	}
+	var t models.Track
+	err := s.pool.QueryRow(ctx,
+		`INSERT INTO tracks (stream_id, track_id, status, first_seen, last_seen, dwell_seconds, frame_count, person_id, match_score,
+		                     gender, gender_confidence, age, age_range, attribute_samples)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		 ON CONFLICT (stream_id, track_id) DO UPDATE SET
+		     status            = CASE WHEN tracks.status = 'ended' THEN tracks.status ELSE EXCLUDED.status END,
+		     last_seen         = GREATEST(tracks.last_seen, EXCLUDED.last_seen),
+		     dwell_seconds     = GREATEST(tracks.dwell_seconds, EXCLUDED.dwell_seconds),
+		     frame_count       = GREATEST(tracks.frame_count, EXCLUDED.frame_count),
+		     person_id         = CASE WHEN tracks.status = 'ended' THEN tracks.person_id ELSE EXCLUDED.person_id END,
+		     match_score       = CASE WHEN tracks.status = 'ended' THEN tracks.match_score ELSE EXCLUDED.match_score END,
+		     gender            = CASE WHEN EXCLUDED.attribute_samples >= tracks.attribute_samples THEN EXCLUDED.gender ELSE tracks.gender END,
+		     gender_confidence = CASE WHEN EXCLUDED.attribute_samples >= tracks.attribute_samples THEN EXCLUDED.gender_confidence ELSE tracks.gender_confidence END,
+		     age               = CASE WHEN EXCLUDED.attribute_samples >= tracks.attribute_samples THEN EXCLUDED.age ELSE tracks.age END,
+		     age_range         = CASE WHEN EXCLUDED.attribute_samples >= tracks.attribute_samples THEN EXCLUDED.age_range ELSE tracks.age_range END,
+		     attribute_samples = GREATEST(tracks.attribute_samples, EXCLUDED.attribute_samples)
+		 RETURNING `+trackColumns,
+		ev.StreamID, ev.TrackID, status, ev.FirstSeen, ev.LastSeen, ev.DwellSeconds, ev.FrameCount, ev.PersonID, ev.MatchScore,
+		ev.Gender, ev.GenderConfidence, ev.Age, ev.AgeRange, ev.AttributeSamples).
+		Scan(trackScanDest(&t)...)
+	if err != nil {
+		return nil, fmt.Errorf("upsert track: %w", err)
+	}
+	return &t, nil
+}
+
+// QueryTracks lists tracks of a stream, newest first.
+func (s *PostgresStore) QueryTracks(ctx context.Context, streamID uuid.UUID, from, to *time.Time, personID *uuid.UUID, status *models.TrackStatus, limit, offset int) ([]models.Track, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	baseWhere := "WHERE stream_id = $1"
+	args := []interface{}{streamID}
+	argIdx := 2
+
+	if from != nil {
+		baseWhere += fmt.Sprintf(" AND last_seen >= $%d", argIdx)
+		args = append(args, *from)
+		argIdx++
+	}
+	if to != nil {
+		baseWhere += fmt.Sprintf(" AND first_seen <= $%d", argIdx)
+		args = append(args, *to)
+		argIdx++
+	}
+	if personID != nil {
+		baseWhere += fmt.Sprintf(" AND person_id = $%d", argIdx)
+		args = append(args, *personID)
+		argIdx++
+	}
+	if status != nil {
+		baseWhere += fmt.Sprintf(" AND status = $%d", argIdx)
+		args = append(args, *status)
+		argIdx++
+	}
+
+	var total int
+	if err := s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM tracks "+baseWhere, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count tracks: %w", err)
+	}
+
+	query := fmt.Sprintf(
+		`SELECT `+trackColumns+`
+		 FROM tracks %s ORDER BY first_seen DESC LIMIT $%d OFFSET $%d`,
+		baseWhere, argIdx, argIdx+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query tracks: %w", err)
+	}
+	defer rows.Close()
+
+	var tracks []models.Track
+	for rows.Next() {
+		var t models.Track
+		if err := rows.Scan(trackScanDest(&t)...); err != nil {
+			return nil, 0, fmt.Errorf("scan track: %w", err)
+		}
+		tracks = append(tracks, t)
+	}
+	return tracks, total, rows.Err()
+}
+
+// --- Analytics ---
+
+// AddAnalyticsCounts adds tripwire/zone count changes to the stream's minute buckets.
+func (s *PostgresStore) AddAnalyticsCounts(ctx context.Context, ev *models.AnalyticsEvent) error {
+	for _, c := range ev.Counts {
+		_, err := s.pool.Exec(ctx,
+			`INSERT INTO stream_analytics (stream_id, bucket, kind, name, in_count, out_count, occupancy, peak_occupancy)
+			 VALUES ($1, date_trunc('minute', $2::timestamptz), $3, $4, $5, $6, $7, $7)
+			 ON CONFLICT (stream_id, bucket, kind, name) DO UPDATE SET
+			     in_count       = stream_analytics.in_count + EXCLUDED.in_count,
+			     out_count      = stream_analytics.out_count + EXCLUDED.out_count,
+			     occupancy      = EXCLUDED.occupancy,
+			     peak_occupancy = GREATEST(stream_analytics.peak_occupancy, EXCLUDED.peak_occupancy)`,
+			ev.StreamID, ev.Timestamp, c.Kind, c.Name, c.In, c.Out, c.Occupancy)
+		if err != nil {
+			return fmt.Errorf("add analytics counts: %w", err)
+		}
+	}
+	return nil
+}
+
+// QueryAnalytics returns the stream's counts in [from, to) re-bucketed to
+// interval (a whole number of minutes), ordered by kind, name and bucket.
+func (s *PostgresStore) QueryAnalytics(ctx context.Context, streamID uuid.UUID, from, to time.Time, interval time.Duration) ([]models.AnalyticsBucket, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT date_bin($4::interval, bucket, TIMESTAMPTZ '2000-01-01') AS b, kind, name,
+		        SUM(in_count), SUM(out_count),
+		        (array_agg(occupancy ORDER BY bucket DESC))[1], MAX(peak_occupancy)
+		 FROM stream_analytics
+		 WHERE stream_id = $1 AND bucket >= $2 AND bucket < $3
+		 GROUP BY b, kind, name
+		 ORDER BY kind, name, b`,
+		streamID, from, to, fmt.Sprintf("%d seconds", int64(interval.Seconds())))
+	if err != nil {
+		return nil, fmt.Errorf("query analytics: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []models.AnalyticsBucket
+	for rows.Next() {
+		b := models.AnalyticsBucket{StreamID: streamID}
+		if err := rows.Scan(&b.Bucket, &b.Kind, &b.Name, &b.In, &b.Out, &b.Occupancy, &b.PeakOccupancy); err != nil {
+			return nil, fmt.Errorf("scan analytics: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+// --- Visitors ---
+
+// ClusterSeed is an unknown track not yet visited by the clustering job,
+// represented by its best-quality embedding.
+type ClusterSeed struct {
+	StreamID     uuid.UUID
+	TrackID      string
+	Embedding    []float32
+	ModelVersion string
+	VisitorID    *uuid.UUID // visitor of the track's earlier, already clustered events
+}
+
+// ClusterNeighbour is an unknown track close to a seed.
+type ClusterNeighbour struct {
+	StreamID  uuid.UUID
+	TrackID   string
+	Distance  float64    // cosine distance of the track's closest event
+	VisitorID *uuid.UUID // nil = not in a visitor yet
+	Promoted  bool       // the visitor was promoted to a person
+}
+
+// ListClusterSeeds returns up to limit unknown tracks with unvisited events
+// older than before, oldest first.
+func (s *PostgresStore) ListClusterSeeds(ctx context.Context, before time.Time, limit int) ([]ClusterSeed, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT stream_id, track_id, embedding, model_version,
+		        (SELECT v.visitor_id FROM events v
+		         WHERE v.stream_id = seed.stream_id AND v.track_id = seed.track_id AND v.visitor_id IS NOT NULL
+		         LIMIT 1)
+		 FROM (
+		     SELECT DISTINCT ON (stream_id, track_id) stream_id, track_id, embedding, model_version, timestamp
+		     FROM events
+		     WHERE NOT clustered AND matched_person_id IS NULL AND embedding IS NOT NULL AND timestamp < $1
+		     ORDER BY stream_id, track_id, quality_score DESC
+		 ) seed
+		 ORDER BY timestamp
+		 LIMIT $2`, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list cluster seeds: %w", err)
+	}
+	defer rows.Close()
+
+	var seeds []ClusterSeed
+	for rows.Next() {
+		var sd ClusterSeed
+		var vec pgvector.Vector
+		if err := rows.Scan(&sd.StreamID, &sd.TrackID, &vec, &sd.ModelVersion, &sd.VisitorID); err != nil {
+			return nil, fmt.Errorf("scan cluster seed: %w", err)
+		}
+		sd.Embedding = vec.Slice()
+		seeds = append(seeds, sd)
+	}
+	return seeds, rows.Err()
+}
+
+// maxEfSearch is the largest hnsw.ef_search pgvector accepts.
+const maxEfSearch = 1000
+
+// ClusterNeighbours returns the unknown tracks other than seed's whose events
+// lie within maxDistance of seed's embedding, closest first. candidates bounds
+// the number of other tracks' events examined.
+func (s *PostgresStore) ClusterNeighbours(ctx context.Context, seed ClusterSeed, maxDistance float64, candidates int) ([]ClusterNeighbour, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cluster neighbours: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The seed's own events are its nearest neighbours; the HNSW scan filters
+	// them out only after finding them, so the window is widened by their count
+	var own int
+	if err := tx.QueryRow(ctx,
+		`SELECT count(*) FROM events WHERE stream_id = $1 AND track_id = $2 AND embedding IS NOT NULL`,
+		seed.StreamID, seed.TrackID).Scan(&own); err != nil {
+		return nil, fmt.Errorf("cluster neighbours: %w", err)
+	}
+	candidates = min(candidates+own, maxEfSearch)
+
+	// The HNSW scan returns at most ef_search rows (default 40)
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", candidates)); err != nil {
+		return nil, fmt.Errorf("cluster neighbours: %w", err)
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT n.stream_id, n.track_id, n.distance, n.visitor_id, v.person_id IS NOT NULL
+		 FROM (
+		     SELECT stream_id, track_id, MIN(distance) AS distance,
+		            (array_agg(visitor_id) FILTER (WHERE visitor_id IS NOT NULL))[1] AS visitor_id
+		     FROM (
+		         SELECT stream_id, track_id, visitor_id, embedding <=> $1 AS distance
+		         FROM events
+		         WHERE embedding IS NOT NULL AND matched_person_id IS NULL AND model_version = $2
+		           AND NOT (stream_id = $5 AND track_id = $6)
+		         ORDER BY embedding <=> $1
+		         LIMIT $3
+		     ) c
+		     WHERE distance <= $4
+		     GROUP BY stream_id, track_id
+		 ) n
+		 LEFT JOIN visitors v ON v.id = n.visitor_id
+		 ORDER BY n.distance`,
+		pgvector.NewVector(seed.Embedding), seed.ModelVersion, candidates, maxDistance, seed.StreamID, seed.TrackID)
+	if err != nil {
+		return nil, fmt.Errorf("cluster neighbours: %w", err)
+	}
+	defer rows.Close()
+
+	var neighbours []ClusterNeighbour
+	for rows.Next() {
+		var n ClusterNeighbour
+		if err := rows.Scan(&n.StreamID, &n.TrackID, &n.Distance, &n.VisitorID, &n.Promoted); err != nil {
+			return nil, fmt.Errorf("scan cluster neighbour: %w", err)
+		}
+		neighbours = append(neighbours, n)
+	}
+	return neighbours, rows.Err()
+}
+
+// CreateVisitor inserts an empty visitor; RefreshVisitors fills in its stats.
+func (s *PostgresStore) CreateVisitor(ctx context.Context, modelVersion string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := s.pool.QueryRow(ctx,
+		`INSERT INTO visitors (model_version) VALUES ($1) RETURNING id`, modelVersion).Scan(&id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("create visitor: %w", err)
+	}
+	return id, nil
+}
+
+// AssignTrackVisitor marks the unknown events of a track as clustered and, if
+// visitorID is set, attaches them to that visitor.
+func (s *PostgresStore) AssignTrackVisitor(ctx context.Context, streamID uuid.UUID, trackID string, visitorID *uuid.UUID) error {
+	_, err := s.pool.Exec(ctx,
+		`UPDATE events SET visitor_id = COALESCE($3, visitor_id), clustered = TRUE
+		 WHERE stream_id = $1 AND track_id = $2 AND matched_person_id IS NULL`,
+		streamID, trackID, visitorID)
+	if err != nil {
+		return fmt.Errorf("assign track visitor: %w", err)
+	}
+	return nil
+}
+
+// MergeVisitors moves the events of all given visitors to the promoted or else
+// the oldest one, deletes the others, and returns the surviving ID. Further
+// promoted visitors are someone else: they are left as they are.
+func (s *PostgresStore) MergeVisitors(ctx context.Context, ids []uuid.UUID) (uuid.UUID, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("merge visitors: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// A promoted visitor keeps its ID so its person link stays meaningful
+	var keep uuid.UUID
+	err = tx.QueryRow(ctx,
+		`SELECT id FROM visitors WHERE id = ANY($1)
+		 ORDER BY person_id IS NULL, created_at LIMIT 1`, ids).Scan(&keep)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("merge visitors: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE events SET visitor_id = $1
+		 WHERE visitor_id IN (SELECT id FROM visitors WHERE id = ANY($2) AND id <> $1 AND person_id IS NULL)`,
+		keep, ids); err != nil {
+		return uuid.Nil, fmt.Errorf("merge visitors: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM visitors WHERE id = ANY($1) AND id <> $2 AND person_id IS NULL`, ids, keep); err != nil {
+		return uuid.Nil, fmt.Errorf("merge visitors: %w", err)
+	}
+	return keep, tx.Commit(ctx)
+}
+
+// RefreshVisitors recomputes the counts and first/last seen of the given
+// visitors from their events, and deletes unpromoted visitors left without events.
+func (s *PostgresStore) RefreshVisitors(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) > 0 {
+		_, err := s.pool.Exec(ctx,
+			`UPDATE visitors v SET
+			     track_count = a.tracks,
+			     event_count = a.events,
+			     first_seen  = a.first_seen,
+			     last_seen   = a.last_seen
+			 FROM (
+			     SELECT visitor_id, COUNT(DISTINCT (stream_id, track_id)) AS tracks, COUNT(*) AS events,
+			            MIN(timestamp) AS first_seen, MAX(timestamp) AS last_seen
+			     FROM events WHERE visitor_id = ANY($1)
+			     GROUP BY visitor_id
+			 ) a
+			 WHERE v.id = a.visitor_id`, ids)
+		if err != nil {
+			return fmt.Errorf("refresh visitors: %w", err)
+		}
+	}
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM visitors v
+		 WHERE person_id IS NULL AND NOT EXISTS (SELECT 1 FROM events e WHERE e.visitor_id = v.id)`)
+	if err != nil {
+		return fmt.Errorf("delete empty visitors: %w", err)
+	}
+	return nil
+}
+
+// visitorColumns lists a visitor with the events of its three best-quality
+// snapshots, each from a different track.
+const visitorColumns = `v.id, v.model_version, v.track_count, v.event_count, v.first_seen, v.last_seen, v.person_id,
+	ARRAY(
+	    SELECT id FROM (
+	        SELECT DISTINCT ON (e.stream_id, e.track_id) e.id, e.quality_score
+	        FROM events e
+	        WHERE e.visitor_id = v.id AND e.snapshot_key <> ''
+	        ORDER BY e.stream_id, e.track_id, e.quality_score DESC
+	    ) best
+	    ORDER BY quality_score DESC LIMIT 3
+	), v.created_at, v.updated_at`
+
+func visitorScanDest(v *models.Visitor) []interface{} {
+	return []interface{}{&v.ID, &v.ModelVersion, &v.TrackCount, &v.EventCount, &v.FirstSeen, &v.LastSeen,
+		&v.PersonID, &v.SnapshotIDs, &v.CreatedAt, &v.UpdatedAt}
+}
+
+// QueryVisitors lists visitors with at least minTracks tracks, most recently
+// seen first. promoted filters on whether the visitor was promoted to a person.
+func (s *PostgresStore) QueryVisitors(ctx context.Context, minTracks int, promoted *bool, limit, offset int) ([]models.Visitor, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	where := "WHERE v.track_count >= $1"
+	args := []interface{}{minTracks}
+	argIdx := 2
+	if promoted != nil {
+		if *promoted {
+			where += " AND v.person_id IS NOT NULL"
+		} else {
+			where += " AND v.person_id IS NULL"
+		}
+	}
+
+	var total int
+	if err := s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM visitors v "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count visitors: %w", err)
+	}
+
+	query := fmt.Sprintf(
+		`SELECT `+visitorColumns+`
+		 FROM visitors v %s ORDER BY v.last_seen DESC LIMIT $%d OFFSET $%d`,
+		where, argIdx, argIdx+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query visitors: %w", err)
+	}
+	defer rows.Close()
+
+	var visitors []models.Visitor
+	for rows.Next() {
+		var v models.Visitor
+		if err := rows.Scan(visitorScanDest(&v)...); err != nil {
+			return nil, 0, fmt.Errorf("scan visitor: %w", err)
+		}
+		visitors = append(visitors, v)
+	}
+	return visitors, total, rows.Err()
+}
+
+func (s *PostgresStore) GetVisitor(ctx context.Context, id uuid.UUID) (*models.Visitor, error) {
+	var v models.Visitor
+	err := s.pool.QueryRow(ctx,
+		`SELECT `+visitorColumns+` FROM visitors v WHERE v.id = $1`, id).
+		Scan(visitorScanDest(&v)...)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get visitor: %w", err)
+	}
+	return &v, nil
+}
+
+// ErrVisitorPromoted is returned by PromoteVisitor for a visitor that already
+// has a person.
+var ErrVisitorPromoted = errors.New("visitor already promoted")
+
+// ErrVisitorRedacted is returned by PromoteVisitor for a visitor whose every
+// event snapshot was stored with the face obscured.
+var ErrVisitorRedacted = errors.New("visitor only has redacted snapshots, cannot enroll from them")
+
+// PromoteVisitor creates a person in collectionID from a visitor: every event
+// embedding of the visitor becomes one of the person's faces, with the event
+// snapshot as its source image. Events with a redacted snapshot are skipped.
+// Returns the person and the number of faces.
+func (s *PostgresStore) PromoteVisitor(ctx context.Context, visitorID, collectionID uuid.UUID, name string, metadata json.RawMessage) (*models.Person, int, error) {
+	if metadata == nil {
+		metadata = json.RawMessage("{}")
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("promote visitor: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var personID *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT person_id FROM visitors WHERE id = $1 FOR UPDATE`, visitorID).Scan(&personID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("promote visitor: %w", err)
+	}
+	if personID != nil {
+		return nil, 0, ErrVisitorPromoted
+	}
+
+	p := &models.Person{
+		ID:           uuid.New(),
+		CollectionID: collectionID,
+		Name:         name,
+		Metadata:     metadata,
+	}
+	err = tx.QueryRow(ctx,
+		`INSERT INTO persons (id, collection_id, name, metadata) VALUES ($1, $2, $3, $4) RETURNING created_at, updated_at`,
+		p.ID, p.CollectionID, p.Name, p.Metadata,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, 0, fmt.Errorf("create person: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO face_embeddings (person_id, embedding, model_version, quality, source_key)
+		 SELECT $1, embedding, model_version, quality_score, snapshot_key
+		 FROM events WHERE visitor_id = $2 AND embedding IS NOT NULL
+		   AND COALESCE(snapshot_key, '') NOT LIKE '%' || $3`,
+		p.ID, visitorID, models.RedactedImageSuffix)
+	if err != nil {
+		return nil, 0, fmt.Errorf("copy visitor embeddings: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		var redacted bool
+		err = tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM events WHERE visitor_id = $1 AND embedding IS NOT NULL)`,
+			visitorID).Scan(&redacted)
+		if err != nil {
+			return nil, 0, fmt.Errorf("promote visitor: %w", err)
+		}
+		if redacted {
+			return nil, 0, ErrVisitorRedacted
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE visitors SET person_id = $1 WHERE id = $2`, p.ID, visitorID); err != nil {
+		return nil, 0, fmt.Errorf("promote visitor: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, 0, fmt.Errorf("promote visitor: %w", err)
+	}
+	return p, int(tag.RowsAffected()), nil
+}
+
+// --- Visitor analytics ---
+
+// TrackFace is one track of a time window with its best embedding and known identities.
+type TrackFace struct {
+	FirstSeen    time.Time  // first event of the track within the window
+	PersonID     *uuid.UUID // matched person of any of its events
+	VisitorID    *uuid.UUID
+	Embedding    []float32 // nil if no event of the track kept one
+	ModelVersion string
+}
+
+// ListTrackFaces returns every track with events in [from, to), optionally of one stream.
+func (s *PostgresStore) ListTrackFaces(ctx context.Context, streamID *uuid.UUID, from, to time.Time) ([]TrackFace, error) {
+	where := "WHERE timestamp >= $1 AND timestamp < $2"
+	args := []interface{}{from, to}
+	if streamID != nil {
+		where += " AND stream_id = $3"
+		args = append(args, *streamID)
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT t.first_seen, t.person_id, t.visitor_id, b.embedding, COALESCE(b.model_version, '')
+		 FROM (
+		     SELECT stream_id, track_id, MIN(timestamp) AS first_seen,
+		            (array_agg(matched_person_id) FILTER (WHERE matched_person_id IS NOT NULL))[1] AS person_id,
+		            (array_agg(visitor_id) FILTER (WHERE visitor_id IS NOT NULL))[1] AS visitor_id
+		     FROM events `+where+`
+		     GROUP BY stream_id, track_id
+		 ) t
+		 LEFT JOIN LATERAL (
+		     SELECT e.embedding, e.model_version FROM events e
+		     WHERE e.stream_id = t.stream_id AND e.track_id = t.track_id AND e.embedding IS NOT NULL
+		     ORDER BY e.quality_score DESC LIMIT 1
+		 ) b ON TRUE
+		 ORDER BY t.first_seen`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list track faces: %w", err)
+	}
+	defer rows.Close()
+
+	var faces []TrackFace
+	for rows.Next() {
+		var f TrackFace
+		var vec *pgvector.Vector
+		if err := rows.Scan(&f.FirstSeen, &f.PersonID, &f.VisitorID, &vec, &f.ModelVersion); err != nil {
+			return nil, fmt.Errorf("scan track face: %w", err)
+		}
+		if vec != nil {
+			f.Embedding = vec.Slice()
+		}
+		faces = append(faces, f)
+	}
+	return faces, rows.Err()
+}
+
+// GetFootfallCache returns the cached buckets of key in [from, to), by bucket start.
+func (s *PostgresStore) GetFootfallCache(ctx context.Context, key string, from, to time.Time) (map[time.Time]models.FootfallBucket, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT bucket, unique_visitors, known, unknown, tracks
+		 FROM visitor_footfall WHERE cache_key = $1 AND bucket >= $2 AND bucket < $3`,
+		key, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("get footfall cache: %w", err)
+	}
+	defer rows.Close()
+
+	cached := make(map[time.Time]models.FootfallBucket)
+	for rows.Next() {
+		var b models.FootfallBucket
+		if err := rows.Scan(&b.Start, &b.UniqueVisitors, &b.Known, &b.Unknown, &b.Tracks); err != nil {
+			return nil, fmt.Errorf("scan footfall cache: %w", err)
+		}
+		cached[b.Start.UTC()] = b
+	}
+	return cached, rows.Err()
+}
+
+// PutFootfallCache stores computed buckets of key.
+func (s *PostgresStore) PutFootfallCache(ctx context.Context, key string, buckets []models.FootfallBucket) error {
+	for _, b := range buckets {
+		_, err := s.pool.Exec(ctx,
+			`INSERT INTO visitor_footfall (cache_key, bucket, unique_visitors, known, unknown, tracks)
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT (cache_key, bucket) DO UPDATE SET
+			     unique_visitors = EXCLUDED.unique_visitors,
+			     known           = EXCLUDED.known,
+			     unknown         = EXCLUDED.unknown,
+			     tracks          = EXCLUDED.tracks,
+			     computed_at     = NOW()`,
+			key, b.Start, b.UniqueVisitors, b.Known, b.Unknown, b.Tracks)
+		if err != nil {
+			return fmt.Errorf("put footfall cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReturningVisitors lists visitors seen on at least minDays distinct days
+// (in time zone tz) within [from, to), optionally on one stream.
+func (s *PostgresStore) ReturningVisitors(ctx context.Context, streamID *uuid.UUID, from, to time.Time, tz string, minDays, limit int) ([]models.ReturningVisitor, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	where := "WHERE e.visitor_id IS NOT NULL AND e.timestamp >= $1 AND e.timestamp < $2"
+	args := []interface{}{from, to, tz, minDays, limit}
+	if streamID != nil {
+		where += " AND e.stream_id = $6"
+		args = append(args, *streamID)
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT e.visitor_id, v.person_id,
+		        COUNT(DISTINCT (e.timestamp AT TIME ZONE $3)::date) AS days,
+		        COUNT(DISTINCT (e.stream_id, e.track_id)),
+		        MIN(e.timestamp), MAX(e.timestamp)
+		 FROM events e JOIN visitors v ON v.id = e.visitor_id
+		 `+where+`
+		 GROUP BY e.visitor_id, v.person_id
+		 HAVING COUNT(DISTINCT (e.timestamp AT TIME ZONE $3)::date) >= $4
+		 ORDER BY days DESC, MAX(e.timestamp) DESC
+		 LIMIT $5`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("returning visitors: %w", err)
+	}
+	defer rows.Close()
+
+	var visitors []models.ReturningVisitor
+	for rows.Next() {
+		var v models.ReturningVisitor
+		if err := rows.Scan(&v.VisitorID, &v.PersonID, &v.Days, &v.Tracks, &v.FirstSeen, &v.LastSeen); err != nil {
+			return nil, fmt.Errorf("scan returning visitor: %w", err)
+		}
+		visitors = append(visitors, v)
+	}
+	return visitors, rows.Err()
+}
+
+// --- Face attribute analytics ---
+
+// QueryAttributeCounts counts, per unit bucket ("hour" or "day" in time zone
+// tz), the tracks given each label of the named face attribute. A track counts
+// once per bucket, with the label of its latest event in it.
+func (s *PostgresStore) QueryAttributeCounts(ctx context.Context, streamID *uuid.UUID, name string, from, to time.Time, unit, tz string) ([]models.AttributeBucket, error) {
+	where := "WHERE timestamp >= $1 AND timestamp < $2 AND attributes ? $3"
+	args := []interface{}{from, to, name, unit, tz}
+	if streamID != nil {
+		where += " AND stream_id = $6"
+		args = append(args, *streamID)
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT bucket AT TIME ZONE $5, label, COUNT(*)
+		 FROM (
+		     SELECT DISTINCT ON (stream_id, track_id, date_trunc($4, timestamp AT TIME ZONE $5))
+		            date_trunc($4, timestamp AT TIME ZONE $5) AS bucket,
+		            attributes -> $3 ->> 'label' AS label
+		     FROM events `+where+`
+		     ORDER BY stream_id, track_id, date_trunc($4, timestamp AT TIME ZONE $5), timestamp DESC
+		 ) t
+		 GROUP BY bucket, label
+		 ORDER BY bucket, label`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query attribute counts: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []models.AttributeBucket
+	for rows.Next() {
+		var b models.AttributeBucket
+		if err := rows.Scan(&b.Bucket, &b.Label, &b.Tracks); err != nil {
+			return nil, fmt.Errorf("scan attribute count: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
@@ -0,0 +1,159 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// arcfaceTemplate is the canonical 5-point landmark layout ArcFace models are
+// trained on, in pixel coordinates of a 112x112 crop:
+// left eye, right eye, nose tip, left mouth corner, right mouth corner.
+var arcfaceTemplate = [5][2]float32{
+	{38.2946, 51.6963},
+	{73.5318, 51.5014},
+	{56.0252, 71.7366},
+	{41.5493, 92.3655},
+	{70.7299, 92.2041},
+}
+
+// arcfaceTemplateSize is the crop size arcfaceTemplate is defined for.
+const arcfaceTemplateSize = 112
+
+// similarityTransform is a 2D similarity (rotation + uniform scale + translation):
+//
+//	x' = a*x - b*y + tx
+//	y' = b*x + a*y + ty
+type similarityTransform struct {
+	a, b, tx, ty float64
+}
+
+// estimateSimilarity finds the least-squares similarity transform mapping src onto dst
+// (Umeyama's method restricted to 2D, no reflection).
+// Returns false if the source points are degenerate (e.g. all identical).
+func estimateSimilarity(src, dst [5][2]float32) (similarityTransform, bool) {
+	const n = 5
+
+	var msx, msy, mdx, mdy float64
+	for i := 0; i < n; i++ {
+		msx += float64(src[i][0])
+		msy += float64(src[i][1])
+		mdx += float64(dst[i][0])
+		mdy += float64(dst[i][1])
+	}
+	msx /= n
+	msy /= n
+	mdx /= n
+	mdy /= n
+
+	var num1, num2, den float64
+	for i := 0; i < n; i++ {
+		sx := float64(src[i][0]) - msx
+		sy := float64(src[i][1]) - msy
+		dx := float64(dst[i][0]) - mdx
+		dy := float64(dst[i][1]) - mdy
+		num1 += sx*dx + sy*dy
+		num2 += sx*dy - sy*dx
+		den += sx*sx + sy*sy
+	}
+	if den < 1e-6 {
+		return similarityTransform{}, false
+	}
+
+	a := num1 / den
+	b := num2 / den
+	return similarityTransform{
+		a:  a,
+		b:  b,
+		tx: mdx - (a*msx - b*msy),
+		ty: mdy - (b*msx + a*msy),
+	}, true
+}
+
+// alignFace warps the face described by landmarks onto the ArcFace template and
+// returns a size×size crop. Returns nil if the landmarks are unusable,
+// in which case callers should fall back to a plain bbox crop.
+func alignFace(img image.Image, landmarks [5][2]float32, size int) image.Image {
+	scale := float32(size) / arcfaceTemplateSize
+	var dst [5][2]float32
+	for i, p := range arcfaceTemplate {
+		dst[i] = [2]float32{p[0] * scale, p[1] * scale}
+	}
+
+	m, ok := estimateSimilarity(landmarks, dst)
+	if !ok {
+		return nil
+	}
+	det := m.a*m.a + m.b*m.b
+	if det < 1e-12 {
+		return nil
+	}
+
+	// Inverse map: for every output pixel find its source location.
+	ia := m.a / det
+	ib := m.b / det
+
+	out := image.NewRGBA(image.Rect(0, 0, size, size))
+	for v := 0; v < size; v++ {
+		for u := 0; u < size; u++ {
+			du := float64(u) - m.tx
+			dv := float64(v) - m.ty
+			sx := ia*du + ib*dv
+			sy := -ib*du + ia*dv
+			r, g, b := sampleBilinear(img, sx, sy)
+			off := out.PixOffset(u, v)
+			out.Pix[off] = r
+			out.Pix[off+1] = g
+			out.Pix[off+2] = b
+			out.Pix[off+3] = 255
+		}
+	}
+	return out
+}
+
+// sampleBilinear returns the bilinearly interpolated RGB value at (x, y).
+// Pixels outside the image are treated as black, matching cv2.warpAffine's
+// default border mode used by InsightFace.
+func sampleBilinear(img image.Image, x, y float64) (uint8, uint8, uint8) {
+	x0 := int(math.Floor(x))
+	y0 := int(math.Floor(y))
+	fx := x - float64(x0)
+	fy := y - float64(y0)
+
+	r00, g00, b00 := rgbAt(img, x0, y0)
+	r10, g10, b10 := rgbAt(img, x0+1, y0)
+	r01, g01, b01 := rgbAt(img, x0, y0+1)
+	r11, g11, b11 := rgbAt(img, x0+1, y0+1)
+
+	lerp := func(c00, c10, c01, c11 float64) uint8 {
+		top := c00 + (c10-c00)*fx
+		bottom := c01 + (c11-c01)*fx
+		return uint8(math.Round(top + (bottom-top)*fy))
+	}
+	return lerp(r00, r10, r01, r11), lerp(g00, g10, g01, g11), lerp(b00, b10, b01, b11)
+}
+
+// rgbAt returns the 8-bit RGB value at (x, y) in image coordinates relative to
+// bounds.Min, or black if out of range.
+func rgbAt(img image.Image, x, y int) (float64, float64, float64) {
+	bounds := img.Bounds()
+	x += bounds.Min.X
+	y += bounds.Min.Y
+	if x < bounds.Min.X || y < bounds.Min.Y || x >= bounds.Max.X || y >= bounds.Max.Y {
+		return 0, 0, 0
+	}
+
+	switch src := img.(type) {
+	case *image.RGBA:
+		off := src.PixOffset(x, y)
+		return float64(src.Pix[off]), float64(src.Pix[off+1]), float64(src.Pix[off+2])
+	case *image.YCbCr:
+		yi := src.YOffset(x, y)
+		ci := src.COffset(x, y)
+		r, g, b := color.YCbCrToRGB(src.Y[yi], src.Cb[ci], src.Cr[ci])
+		return float64(r), float64(g), float64(b)
+	default:
+		r, g, b, _ := img.At(x, y).RGBA()
+		return float64(r >> 8), float64(g >> 8), float64(b >> 8)
+	}
+}
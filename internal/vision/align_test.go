@@ -0,0 +1,106 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// transformed returns the ArcFace template rotated by angle (radians), scaled
+// and moved, as a face would appear in a frame.
+func transformed(angle, scale, tx, ty float64) [5][2]float32 {
+	c, s := math.Cos(angle)*scale, math.Sin(angle)*scale
+	var out [5][2]float32
+	for i, p := range arcfaceTemplate {
+		x, y := float64(p[0]), float64(p[1])
+		out[i] = [2]float32{float32(c*x - s*y + tx), float32(s*x + c*y + ty)}
+	}
+	return out
+}
+
+func TestEstimateSimilarity(t *testing.T) {
+	tests := []struct {
+		name                 string
+		angle, scale, tx, ty float64
+	}{
+		{name: "identity", scale: 1},
+		{name: "shifted", scale: 1, tx: 200, ty: 80},
+		{name: "larger face", scale: 2.5, tx: 300, ty: 120},
+		{name: "smaller tilted face", angle: 0.4, scale: 0.6, tx: 50, ty: 400},
+		{name: "tilted the other way", angle: -0.7, scale: 1.8, tx: 640, ty: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := transformed(tt.angle, tt.scale, tt.tx, tt.ty)
+			m, ok := estimateSimilarity(src, arcfaceTemplate)
+			if !ok {
+				t.Fatal("degenerate")
+			}
+			// Mapping the face back must land exactly on the template
+			for i, p := range src {
+				x, y := float64(p[0]), float64(p[1])
+				gx, gy := m.a*x-m.b*y+m.tx, m.b*x+m.a*y+m.ty
+				want := arcfaceTemplate[i]
+				if math.Abs(gx-float64(want[0])) > 1e-3 || math.Abs(gy-float64(want[1])) > 1e-3 {
+					t.Errorf("point %d maps to (%.3f, %.3f), want %v", i, gx, gy, want)
+				}
+			}
+			if scale := math.Hypot(m.a, m.b); math.Abs(scale-1/tt.scale) > 1e-4 {
+				t.Errorf("scale = %v, want %v", scale, 1/tt.scale)
+			}
+		})
+	}
+
+	var same [5][2]float32
+	for i := range same {
+		same[i] = [2]float32{10, 10}
+	}
+	if _, ok := estimateSimilarity(same, arcfaceTemplate); ok {
+		t.Error("coincident landmarks accepted")
+	}
+}
+
+func TestAlignFace(t *testing.T) {
+	tests := []struct {
+		name                 string
+		angle, scale, tx, ty float64
+		size                 int
+	}{
+		{name: "upright", scale: 2, tx: 100, ty: 60, size: 112},
+		{name: "tilted", angle: 0.5, scale: 1.5, tx: 200, ty: 40, size: 112},
+		{name: "larger crop", angle: -0.3, scale: 3, tx: 80, ty: 90, size: 224},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A black frame with a white dot on each landmark
+			lm := transformed(tt.angle, tt.scale, tt.tx, tt.ty)
+			img := image.NewRGBA(image.Rect(0, 0, 640, 480))
+			for _, p := range lm {
+				for dy := -3; dy <= 3; dy++ {
+					for dx := -3; dx <= 3; dx++ {
+						img.Set(int(p[0])+dx, int(p[1])+dy, color.White)
+					}
+				}
+			}
+
+			out := alignFace(img, lm, tt.size)
+			if out == nil {
+				t.Fatal("alignFace returned nil")
+			}
+			if b := out.Bounds(); b.Dx() != tt.size || b.Dy() != tt.size {
+				t.Fatalf("crop is %v, want %dx%d", b, tt.size, tt.size)
+			}
+			k := float32(tt.size) / arcfaceTemplateSize
+			for i, p := range arcfaceTemplate {
+				r, _, _, _ := out.At(int(p[0]*k), int(p[1]*k)).RGBA()
+				if r>>8 < 128 {
+					t.Errorf("landmark %d is not at its template position %v", i, p)
+				}
+			}
+			if r, _, _, _ := out.At(tt.size/2, tt.size/8).RGBA(); r>>8 > 0 {
+				t.Error("forehead is not black")
+			}
+		})
+	}
+}
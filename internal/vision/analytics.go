@@ -0,0 +1,216 @@
+package vision
+
+import (
+	"sync"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+// Counter turns the movement of one stream's tracks into tripwire crossings
+// and zone entries/exits. Positions are track bbox centres in normalised frame
+// coordinates, so counts do not depend on frame_width.
+type Counter struct {
+	mu       sync.Mutex
+	frame    int
+	tracks   map[string]*countedTrack
+	settings *models.StreamSettings // of the latest Update
+}
+
+type countedTrack struct {
+	x, y      float64
+	zones     map[string]bool // zones the track is inside
+	lastFrame int
+}
+
+func NewCounter() *Counter {
+	return &Counter{tracks: make(map[string]*countedTrack)}
+}
+
+// Update advances the counter by one frame of tracker updates and returns the
+// counters that changed. Tracks not updated for more than maxAge frames (e.g.
+// replaced by re-identification) are treated as ended.
+func (c *Counter) Update(updates []TrackUpdate, width, height int, s *models.StreamSettings, maxAge int) []models.AnalyticsCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.frame++
+	c.settings = s
+	if s == nil || (len(s.Tripwires) == 0 && len(s.Zones) == 0) {
+		clear(c.tracks)
+		return nil
+	}
+
+	d := newCountDeltas(s)
+	for _, upd := range updates {
+		if upd.Ended {
+			c.leave(upd.Track.ID, d)
+			continue
+		}
+
+		bb := upd.Track.BBox
+		x := float64(bb[0]+bb[2]) / 2 / float64(width)
+		y := float64(bb[1]+bb[3]) / 2 / float64(height)
+
+		ct, ok := c.tracks[upd.Track.ID]
+		if !ok {
+			ct = &countedTrack{x: x, y: y, zones: make(map[string]bool)}
+			c.tracks[upd.Track.ID] = ct
+		} else {
+			for i, l := range s.Tripwires {
+				switch crossing(l, ct.x, ct.y, x, y) {
+				case 1:
+					d.lines[i].In++
+				case -1:
+					d.lines[i].Out++
+				}
+			}
+		}
+		ct.x, ct.y = x, y
+		ct.lastFrame = c.frame
+
+		for i, z := range s.Zones {
+			inside := z.Polygon.Contains(x, y)
+			switch {
+			case inside && !ct.zones[z.Name]:
+				ct.zones[z.Name] = true
+				d.zones[i].In++
+			case !inside && ct.zones[z.Name]:
+				delete(ct.zones, z.Name)
+				d.zones[i].Out++
+			}
+		}
+	}
+
+	for id, ct := range c.tracks {
+		if c.frame-ct.lastFrame > maxAge {
+			c.leave(id, d)
+		}
+	}
+	return c.changed(d)
+}
+
+// Merge hands the counting state of track from over to track into after the
+// tracker re-identified from as the earlier track into. The earlier track's
+// position is stale, so it leaves its zones and from's zones carry over.
+func (c *Counter) Merge(from, into string) []models.AnalyticsCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ct, ok := c.tracks[from]
+	if !ok {
+		return nil
+	}
+	delete(c.tracks, from)
+
+	d := newCountDeltas(c.settings)
+	c.leave(into, d)
+	c.tracks[into] = ct
+	return c.changed(d)
+}
+
+// Flush ends every track, e.g. because the stream stopped sending frames.
+func (c *Counter) Flush() []models.AnalyticsCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.tracks) == 0 {
+		return nil
+	}
+	d := newCountDeltas(c.settings)
+	for id := range c.tracks {
+		c.leave(id, d)
+	}
+	return c.changed(d)
+}
+
+// leave removes a track, counting an exit for every zone it was in.
+func (c *Counter) leave(id string, d *countDeltas) {
+	ct, ok := c.tracks[id]
+	if !ok {
+		return
+	}
+	for name := range ct.zones {
+		if z := d.zone(name); z != nil {
+			z.Out++
+		}
+	}
+	delete(c.tracks, id)
+}
+
+// changed fills in zone occupancy and returns the counters with activity, in
+// config order.
+func (c *Counter) changed(d *countDeltas) []models.AnalyticsCount {
+	var out []models.AnalyticsCount
+	for _, l := range d.lines {
+		if l.In != 0 || l.Out != 0 {
+			out = append(out, l)
+		}
+	}
+	for _, z := range d.zones {
+		if z.In == 0 && z.Out == 0 {
+			continue
+		}
+		for _, ct := range c.tracks {
+			if ct.zones[z.Name] {
+				z.Occupancy++
+			}
+		}
+		out = append(out, z)
+	}
+	return out
+}
+
+type countDeltas struct {
+	lines []models.AnalyticsCount
+	zones []models.AnalyticsCount
+}
+
+func newCountDeltas(s *models.StreamSettings) *countDeltas {
+	d := &countDeltas{
+		lines: make([]models.AnalyticsCount, len(s.Tripwires)),
+		zones: make([]models.AnalyticsCount, len(s.Zones)),
+	}
+	for i, l := range s.Tripwires {
+		d.lines[i] = models.AnalyticsCount{Kind: models.AnalyticsLine, Name: l.Name}
+	}
+	for i, z := range s.Zones {
+		d.zones[i] = models.AnalyticsCount{Kind: models.AnalyticsZone, Name: z.Name}
+	}
+	return d
+}
+
+// zone returns the delta of the named zone, or nil if it is no longer configured.
+func (d *countDeltas) zone(name string) *models.AnalyticsCount {
+	for i := range d.zones {
+		if d.zones[i].Name == name {
+			return &d.zones[i]
+		}
+	}
+	return nil
+}
+
+// crossing reports whether the move (x0, y0) → (x1, y1) crosses the tripwire:
+// 1 from its left to its right ("in"), -1 the other way, 0 not at all.
+func crossing(l models.Tripwire, x0, y0, x1, y1 float64) int {
+	side := func(x, y float64) float64 {
+		return (l.To[0]-l.From[0])*(y-l.From[1]) - (l.To[1]-l.From[1])*(x-l.From[0])
+	}
+	s0, s1 := side(x0, y0), side(x1, y1)
+	if s0 == 0 || s1 == 0 || (s0 > 0) == (s1 > 0) {
+		return 0
+	}
+
+	// The move must also pass between the line's end points.
+	moveSide := func(x, y float64) float64 {
+		return (x1-x0)*(y-y0) - (y1-y0)*(x-x0)
+	}
+	if (moveSide(l.From[0], l.From[1]) > 0) == (moveSide(l.To[0], l.To[1]) > 0) {
+		return 0
+	}
+
+	// In image coordinates (y down) a positive side is to the right of From→To.
+	if s1 > 0 {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,132 @@
+package vision
+
+import (
+	"testing"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+// move is the bbox centre of a track in one frame, in normalised coordinates.
+type move struct {
+	id    string
+	x, y  float64
+	ended bool
+}
+
+// runCounter feeds one update per frame to a new Counter and sums the
+// reported counts per counter name; Occupancy is the latest reported one.
+func runCounter(s *models.StreamSettings, moves []move) map[string]models.AnalyticsCount {
+	const size = 100
+	c := NewCounter()
+	got := make(map[string]models.AnalyticsCount)
+	for _, m := range moves {
+		tr := &Track{ID: m.id, BBox: [4]float32{
+			float32(m.x*size) - 5, float32(m.y*size) - 5,
+			float32(m.x*size) + 5, float32(m.y*size) + 5,
+		}}
+		for _, cnt := range c.Update([]TrackUpdate{{Track: tr, Ended: m.ended}}, size, size, s, 30) {
+			sum := got[cnt.Name]
+			sum.In += cnt.In
+			sum.Out += cnt.Out
+			sum.Occupancy = cnt.Occupancy
+			got[cnt.Name] = sum
+		}
+	}
+	return got
+}
+
+func TestCounterTripwire(t *testing.T) {
+	// A vertical line drawn top to bottom: its left is the right half of the frame.
+	s := &models.StreamSettings{Tripwires: []models.Tripwire{
+		{Name: "door", From: [2]float64{0.5, 0.2}, To: [2]float64{0.5, 0.8}},
+	}}
+
+	tests := []struct {
+		name    string
+		moves   []move
+		in, out int
+	}{
+		{name: "crossing in", moves: []move{{id: "a", x: 0.7, y: 0.5}, {id: "a", x: 0.3, y: 0.5}}, in: 1},
+		{name: "crossing out", moves: []move{{id: "a", x: 0.3, y: 0.5}, {id: "a", x: 0.7, y: 0.5}}, out: 1},
+		{name: "in and back out", moves: []move{
+			{id: "a", x: 0.7, y: 0.5}, {id: "a", x: 0.3, y: 0.5}, {id: "a", x: 0.7, y: 0.5},
+		}, in: 1, out: 1},
+		{name: "staying on one side", moves: []move{
+			{id: "a", x: 0.9, y: 0.5}, {id: "a", x: 0.6, y: 0.3}, {id: "a", x: 0.8, y: 0.7},
+		}},
+		{name: "passing beyond an end", moves: []move{{id: "a", x: 0.7, y: 0.9}, {id: "a", x: 0.3, y: 0.9}}},
+		{name: "first sighting beyond the line", moves: []move{{id: "a", x: 0.3, y: 0.5}}},
+		{name: "two tracks", moves: []move{
+			{id: "a", x: 0.7, y: 0.5}, {id: "b", x: 0.3, y: 0.4},
+			{id: "a", x: 0.3, y: 0.5}, {id: "b", x: 0.7, y: 0.4},
+		}, in: 1, out: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runCounter(s, tt.moves)["door"]
+			if got.In != tt.in || got.Out != tt.out {
+				t.Errorf("in/out = %d/%d, want %d/%d", got.In, got.Out, tt.in, tt.out)
+			}
+		})
+	}
+}
+
+func TestCounterZone(t *testing.T) {
+	s := &models.StreamSettings{Zones: []models.Zone{
+		{Name: "desk", Polygon: models.Polygon{{0, 0}, {0.5, 0}, {0.5, 0.5}, {0, 0.5}}},
+	}}
+
+	tests := []struct {
+		name               string
+		moves              []move
+		in, out, occupancy int
+	}{
+		{name: "appearing inside", moves: []move{{id: "a", x: 0.2, y: 0.2}}, in: 1, occupancy: 1},
+		{name: "walking in", moves: []move{{id: "a", x: 0.8, y: 0.2}, {id: "a", x: 0.2, y: 0.2}}, in: 1, occupancy: 1},
+		{name: "walking in and out", moves: []move{
+			{id: "a", x: 0.8, y: 0.2}, {id: "a", x: 0.2, y: 0.2}, {id: "a", x: 0.2, y: 0.8},
+		}, in: 1, out: 1},
+		{name: "moving inside", moves: []move{{id: "a", x: 0.1, y: 0.1}, {id: "a", x: 0.4, y: 0.4}}, in: 1, occupancy: 1},
+		{name: "ending inside", moves: []move{{id: "a", x: 0.2, y: 0.2}, {id: "a", ended: true}}, in: 1, out: 1},
+		{name: "two inside, one leaves", moves: []move{
+			{id: "a", x: 0.2, y: 0.2}, {id: "b", x: 0.3, y: 0.3}, {id: "b", x: 0.9, y: 0.9},
+		}, in: 2, out: 1, occupancy: 1},
+		{name: "never inside", moves: []move{{id: "a", x: 0.8, y: 0.8}, {id: "a", x: 0.9, y: 0.6}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runCounter(s, tt.moves)["desk"]
+			if got.In != tt.in || got.Out != tt.out || got.Occupancy != tt.occupancy {
+				t.Errorf("in/out/occupancy = %d/%d/%d, want %d/%d/%d",
+					got.In, got.Out, got.Occupancy, tt.in, tt.out, tt.occupancy)
+			}
+		})
+	}
+}
+
+func TestCounterFlushAndMerge(t *testing.T) {
+	s := &models.StreamSettings{Zones: []models.Zone{
+		{Name: "desk", Polygon: models.Polygon{{0, 0}, {0.5, 0}, {0.5, 0.5}, {0, 0.5}}},
+	}}
+	inside := func(id string) []TrackUpdate {
+		return []TrackUpdate{{Track: &Track{ID: id, BBox: [4]float32{15, 15, 25, 25}}}}
+	}
+
+	c := NewCounter()
+	c.Update(inside("a"), 100, 100, s, 30)
+	c.Update(inside("b"), 100, 100, s, 30)
+
+	// b is re-identified as a: a's stale position leaves, b's carries over.
+	counts := c.Merge("b", "a")
+	if len(counts) != 1 || counts[0].Out != 1 || counts[0].Occupancy != 1 {
+		t.Fatalf("Merge = %+v, want one exit leaving an occupancy of 1", counts)
+	}
+
+	counts = c.Flush()
+	if len(counts) != 1 || counts[0].Out != 1 || counts[0].Occupancy != 0 {
+		t.Fatalf("Flush = %+v, want one exit leaving the zone empty", counts)
+	}
+	if counts := c.Flush(); counts != nil {
+		t.Fatalf("second Flush = %+v, want nil", counts)
+	}
+}
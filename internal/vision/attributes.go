@@ -11,43 +11,39 @@ import (
 type GenderAge struct {
 	Gender           string  // "male" or "female"
 	GenderConfidence float32 // 0.0 to 1.0
+	GenderLogOdds    float32 // log(P(male) / P(female))
 	Age              int
 	AgeRange         string // e.g. "30-35"
 }
 
-// AttributePredictor predicts gender and age using InsightFace genderage model.
+// AttributePredictor predicts gender and age with an InsightFace genderage-style model.
+// The session has a dynamic batch dimension, so several faces can share one run.
 type AttributePredictor struct {
-	session      *ort.AdvancedSession
-	inputTensor  *ort.Tensor[float32]
-	outputTensor *ort.Tensor[float32]
-	inputW       int
-	inputH       int
+	session   *ort.DynamicAdvancedSession
+	inputW    int
+	inputH    int
+	maxBatch  int
+	mean, std [3]float32
+	ageScale  float32
+	inBuf     []float32 // reused input buffer, maxBatch faces
+	outBuf    []float32 // reused output buffer, maxBatch × 3 values
 }
 
-// NewAttributePredictor loads the gender/age ONNX model.
-// opts may be nil (ORT defaults) or a pre-configured *ort.SessionOptions.
-func NewAttributePredictor(modelPath string, opts *ort.SessionOptions) (*AttributePredictor, error) {
-	// InsightFace genderage model expects 96x96 input
-	inputW, inputH := 96, 96
-
-	inputShape := ort.NewShape(1, 3, int64(inputH), int64(inputW))
-	inputTensor, err := ort.NewEmptyTensor[float32](inputShape)
-	if err != nil {
-		return nil, fmt.Errorf("create input tensor: %w", err)
-	}
+// attributeOutputs is the per-face output size: [female_logit, male_logit, age / age_scale].
+const attributeOutputs = 3
 
-	// Output: [1, 3] — [female_logit, male_logit, age_normalized]
-	outputShape := ort.NewShape(1, 3)
-	outputTensor, err := ort.NewEmptyTensor[float32](outputShape)
-	if err != nil {
-		return nil, fmt.Errorf("create output tensor: %w", err)
+// NewAttributePredictor loads the gender/age model described by spec.
+// maxBatch caps the number of faces per session run (<1 = 1).
+// opts may be nil (ORT defaults) or a pre-configured *ort.SessionOptions.
+func NewAttributePredictor(spec ModelSpec, maxBatch int, opts *ort.SessionOptions) (*AttributePredictor, error) {
+	inputW, inputH := spec.Size[0], spec.Size[1]
+	if maxBatch < 1 {
+		maxBatch = 1
 	}
 
-	session, err := ort.NewAdvancedSession(modelPath,
-		[]string{"data"},
-		[]string{"fc1"},
-		[]ort.Value{inputTensor},
-		[]ort.Value{outputTensor},
+	session, err := ort.NewDynamicAdvancedSession(spec.File,
+		[]string{spec.Input},
+		spec.Outputs,
 		opts,
 	)
 	if err != nil {
@@ -55,31 +51,58 @@ func NewAttributePredictor(modelPath string, opts *ort.SessionOptions) (*Attribu
 	}
 
 	return &AttributePredictor{
-		session:      session,
-		inputTensor:  inputTensor,
-		outputTensor: outputTensor,
-		inputW:       inputW,
-		inputH:       inputH,
+		session:  session,
+		inputW:   inputW,
+		inputH:   inputH,
+		maxBatch: maxBatch,
+		mean:     spec.Mean,
+		std:      spec.Std,
+		ageScale: spec.AgeScale,
+		inBuf:    make([]float32, maxBatch*3*inputH*inputW),
+		outBuf:   make([]float32, maxBatch*attributeOutputs),
 	}, nil
 }
 
 // Predict runs gender/age prediction on a face crop.
-// faceData should be CHW format [3, 96, 96], normalized.
+// faceData should be CHW format [3, inputH, inputW], normalized.
 func (p *AttributePredictor) Predict(faceData []float32) (*GenderAge, error) {
-	// Copy input data into the input tensor
-	inputSlice := p.inputTensor.GetData()
-	copy(inputSlice, faceData)
-
-	if err := p.session.Run(); err != nil {
-		return nil, fmt.Errorf("run attributes: %w", err)
+	out, err := p.PredictBatch([][]float32{faceData})
+	if err != nil {
+		return nil, err
 	}
+	return out[0], nil
+}
 
-	// Read output directly from the output tensor
-	data := p.outputTensor.GetData()
-	if len(data) < 3 {
-		return nil, fmt.Errorf("unexpected output size: %d", len(data))
+// PredictBatch runs gender/age prediction on several face crops (each CHW
+// [3, inputH, inputW], normalized) in as few session runs as maxBatch allows.
+func (p *AttributePredictor) PredictBatch(faces [][]float32) ([]*GenderAge, error) {
+	out := make([]*GenderAge, 0, len(faces))
+	faceSize := 3 * p.inputH * p.inputW
+
+	for len(faces) > 0 {
+		n := min(len(faces), p.maxBatch)
+		for i, f := range faces[:n] {
+			copy(p.inBuf[i*faceSize:(i+1)*faceSize], f)
+		}
+
+		err := runBatch(p.session,
+			p.inBuf[:n*faceSize], ort.NewShape(int64(n), 3, int64(p.inputH), int64(p.inputW)),
+			p.outBuf[:n*attributeOutputs], ort.NewShape(int64(n), attributeOutputs))
+		if err != nil {
+			return nil, fmt.Errorf("run attributes: %w", err)
+		}
+
+		for i := 0; i < n; i++ {
+			out = append(out, decodeGenderAge(p.outBuf[i*attributeOutputs:(i+1)*attributeOutputs], p.ageScale))
+		}
+		faces = faces[n:]
 	}
+	return out, nil
+}
 
+// decodeGenderAge converts one face's model output into gender and age.
+// ageScale recovers years from the model's age output.
+func decodeGenderAge(data []float32, ageScale float32) *GenderAge {
 	// InsightFace genderage fc1 output = [female_logit, male_logit, age_normalized]
 	// fc1 is Concat of fullyconnected0 (gender, 2 classes) + fullyconnected1 (age, 1 value)
 	femaleLogit := data[0]
@@ -100,8 +123,8 @@ func (p *AttributePredictor) Predict(faceData []float32) (*GenderAge, error) {
 		genderConf = 1 - maleProbability
 	}
 
-	// Age: multiply by 100 to recover real age (InsightFace normalizes age/100 during training)
-	age := int(math.Round(float64(ageNorm) * 100))
+	// Age: multiply by age_scale to recover real age (InsightFace normalizes age/100 during training)
+	age := int(math.Round(float64(ageNorm * ageScale)))
 	if age < 0 {
 		age = 0
 	}
@@ -109,17 +132,53 @@ func (p *AttributePredictor) Predict(faceData []float32) (*GenderAge, error) {
 		age = 100
 	}
 
-	// Compute age range (±2.5 years bucket)
-	lower := (age / 5) * 5
-	upper := lower + 5
-	ageRange := fmt.Sprintf("%d-%d", lower, upper)
-
 	return &GenderAge{
 		Gender:           gender,
 		GenderConfidence: genderConf,
+		GenderLogOdds:    maleLogit - femaleLogit,
 		Age:              age,
-		AgeRange:         ageRange,
-	}, nil
+		AgeRange:         ageRange(age),
+	}
+}
+
+// ageRange returns the 5-year bucket containing age, e.g. "30-35".
+func ageRange(age int) string {
+	lower := (age / 5) * 5
+	return fmt.Sprintf("%d-%d", lower, lower+5)
+}
+
+// attributeSmoother aggregates the per-pass gender/age predictions of one track:
+// a weighted running mean for age and accumulated (weighted) log-odds for gender.
+// Weights are the face quality of the crop each prediction came from.
+type attributeSmoother struct {
+	ageSum    float64
+	weightSum float64
+	logOdds   float64
+	samples   int
+}
+
+// add folds one prediction into the aggregate and returns the smoothed result.
+func (s *attributeSmoother) add(ga *GenderAge, weight float32) GenderAge {
+	w := math.Max(float64(weight), 0.01)
+	s.ageSum += w * float64(ga.Age)
+	s.weightSum += w
+	s.logOdds += w * float64(ga.GenderLogOdds)
+	s.samples++
+
+	age := int(math.Round(s.ageSum / s.weightSum))
+	maleProbability := 1 / (1 + math.Exp(-s.logOdds))
+	out := GenderAge{
+		Gender:           "male",
+		GenderConfidence: float32(maleProbability),
+		GenderLogOdds:    float32(s.logOdds),
+		Age:              age,
+		AgeRange:         ageRange(age),
+	}
+	if s.logOdds < 0 {
+		out.Gender = "female"
+		out.GenderConfidence = float32(1 - maleProbability)
+	}
+	return out
 }
 
 // InputSize returns the expected face crop dimensions.
@@ -131,10 +190,4 @@ func (p *AttributePredictor) Close() {
 	if p.session != nil {
 		p.session.Destroy()
 	}
-	if p.inputTensor != nil {
-		p.inputTensor.Destroy()
-	}
-	if p.outputTensor != nil {
-		p.outputTensor.Destroy()
-	}
 }
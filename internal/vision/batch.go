@@ -0,0 +1,188 @@
+package vision
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/your-org/fd/internal/config"
+	"github.com/your-org/fd/internal/observability"
+)
+
+// faceBatchResult holds the per-face model outputs of one batch, in input order.
+// An embedding error fails the whole batch; attribute errors only drop the attributes.
+type faceBatchResult struct {
+	embeddings [][]float32
+	embedErr   error
+	attributes []*GenderAge
+	attrErr    error
+}
+
+// runFaceModels runs the embedding and attribute models once each over a batch of crops.
+func runFaceModels(emb *Embedder, attr *AttributePredictor, embInputs, attrInputs [][]float32) faceBatchResult {
+	var res faceBatchResult
+
+	start := time.Now()
+	res.embeddings, res.embedErr = emb.ExtractBatch(embInputs)
+	observability.InferenceDuration.WithLabelValues("embed").Observe(time.Since(start).Seconds())
+	observability.InferenceBatchSize.WithLabelValues("embed").Observe(float64(len(embInputs)))
+	if res.embedErr != nil {
+		return res
+	}
+
+	start = time.Now()
+	res.attributes, res.attrErr = attr.PredictBatch(attrInputs)
+	observability.InferenceDuration.WithLabelValues("attrs").Observe(time.Since(start).Seconds())
+	observability.InferenceBatchSize.WithLabelValues("attrs").Observe(float64(len(attrInputs)))
+	return res
+}
+
+// Batcher merges the embedding and attribute work of several pipelines (and so
+// several frames and streams) into shared batched session runs. A request waits
+// at most window for others to join before its batch is run.
+// The Batcher owns its own sessions; Run must be the only goroutine using them.
+type Batcher struct {
+	embedder   *Embedder
+	attributes *AttributePredictor
+	window     time.Duration
+	maxBatch   int
+	reqs       chan *batchRequest
+}
+
+type batchRequest struct {
+	embInputs  [][]float32
+	attrInputs [][]float32
+	result     chan faceBatchResult
+}
+
+// NewBatcher loads a dedicated embedding and attribute session pair.
+func NewBatcher(cfg config.VisionConfig) (*Batcher, error) {
+	manifest, err := LoadManifest(cfg.ModelsDir)
+	if err != nil {
+		return nil, err
+	}
+	embSpec, err := manifest.Model(RoleEmbedder)
+	if err != nil {
+		return nil, err
+	}
+	attrSpec, err := manifest.Model(RoleAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	embOpts, err := newSessionOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	emb, err := NewEmbedder(embSpec, cfg.BatchSize, embOpts)
+	embOpts.Destroy()
+	if err != nil {
+		return nil, fmt.Errorf("load embedder: %w", err)
+	}
+
+	attrOpts, err := newSessionOptions(cfg)
+	if err != nil {
+		emb.Close()
+		return nil, err
+	}
+	attr, err := NewAttributePredictor(attrSpec, cfg.BatchSize, attrOpts)
+	attrOpts.Destroy()
+	if err != nil {
+		emb.Close()
+		return nil, fmt.Errorf("load attributes: %w", err)
+	}
+
+	slog.Info("inference batcher ready", "batch_size", cfg.BatchSize, "window", cfg.BatchWindow)
+
+	return &Batcher{
+		embedder:   emb,
+		attributes: attr,
+		window:     cfg.BatchWindow,
+		maxBatch:   max(cfg.BatchSize, 1),
+		reqs:       make(chan *batchRequest, 64),
+	}, nil
+}
+
+// Infer submits one frame's crops and waits for their results.
+func (b *Batcher) Infer(ctx context.Context, embInputs, attrInputs [][]float32) faceBatchResult {
+	req := &batchRequest{
+		embInputs:  embInputs,
+		attrInputs: attrInputs,
+		result:     make(chan faceBatchResult, 1),
+	}
+	select {
+	case b.reqs <- req:
+	case <-ctx.Done():
+		return faceBatchResult{embedErr: ctx.Err()}
+	}
+	select {
+	case res := <-req.result:
+		return res
+	case <-ctx.Done():
+		return faceBatchResult{embedErr: ctx.Err()}
+	}
+}
+
+// Run collects requests into batches until ctx is cancelled.
+func (b *Batcher) Run(ctx context.Context) {
+	for {
+		var first *batchRequest
+		select {
+		case <-ctx.Done():
+			return
+		case first = <-b.reqs:
+		}
+
+		batch := []*batchRequest{first}
+		n := len(first.embInputs)
+		timer := time.NewTimer(b.window)
+	collect:
+		for n < b.maxBatch {
+			select {
+			case req := <-b.reqs:
+				batch = append(batch, req)
+				n += len(req.embInputs)
+			case <-timer.C:
+				break collect
+			case <-ctx.Done():
+				break collect
+			}
+		}
+		timer.Stop()
+
+		b.process(batch, n)
+	}
+}
+
+// process runs one merged batch and hands every request its slice of the results.
+func (b *Batcher) process(batch []*batchRequest, n int) {
+	embInputs := make([][]float32, 0, n)
+	attrInputs := make([][]float32, 0, n)
+	for _, req := range batch {
+		embInputs = append(embInputs, req.embInputs...)
+		attrInputs = append(attrInputs, req.attrInputs...)
+	}
+
+	res := runFaceModels(b.embedder, b.attributes, embInputs, attrInputs)
+
+	off := 0
+	for _, req := range batch {
+		k := len(req.embInputs)
+		out := faceBatchResult{embedErr: res.embedErr, attrErr: res.attrErr}
+		if res.embedErr == nil {
+			out.embeddings = res.embeddings[off : off+k]
+		}
+		if res.embedErr == nil && res.attrErr == nil {
+			out.attributes = res.attributes[off : off+k]
+		}
+		req.result <- out
+		off += k
+	}
+}
+
+// Close releases the batcher's sessions. Call it after Run has returned.
+func (b *Batcher) Close() {
+	b.embedder.Close()
+	b.attributes.Close()
+}
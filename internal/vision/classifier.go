@@ -0,0 +1,150 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+
+	ort "github.com/yalue/onnxruntime_go"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+// Classifier predicts one categorical face attribute (mask, eyewear, hat, ...)
+// from the face crop. Its outputs are logits, one per label.
+type Classifier struct {
+	session   *ort.DynamicAdvancedSession
+	name      string
+	labels    []string
+	penalty   map[string]float32 // occlusion penalty per label
+	inputW    int
+	inputH    int
+	maxBatch  int
+	mean, std [3]float32
+	cropScale float32
+	bgr       bool
+	inBuf     []float32 // reused input buffer, maxBatch faces
+	outBuf    []float32 // reused output buffer, maxBatch × labels logits
+}
+
+// NewClassifier loads the classifier described by spec.
+// maxBatch caps the number of faces per session run (<1 = 1).
+// opts may be nil (ORT defaults) or a pre-configured *ort.SessionOptions.
+func NewClassifier(spec ModelSpec, maxBatch int, opts *ort.SessionOptions) (*Classifier, error) {
+	inputW, inputH := spec.Size[0], spec.Size[1]
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+
+	session, err := ort.NewDynamicAdvancedSession(spec.File,
+		[]string{spec.Input},
+		spec.Outputs,
+		opts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create %s classifier session: %w", spec.Name, err)
+	}
+
+	return &Classifier{
+		session:   session,
+		name:      spec.Name,
+		labels:    spec.Labels,
+		penalty:   spec.OcclusionPenalty,
+		inputW:    inputW,
+		inputH:    inputH,
+		maxBatch:  maxBatch,
+		mean:      spec.Mean,
+		std:       spec.Std,
+		cropScale: spec.CropScale,
+		bgr:       spec.BGR,
+		inBuf:     make([]float32, maxBatch*3*inputH*inputW),
+		outBuf:    make([]float32, maxBatch*len(spec.Labels)),
+	}, nil
+}
+
+// Name returns the attribute the classifier predicts.
+func (c *Classifier) Name() string {
+	return c.name
+}
+
+// PredictBatch returns the label probabilities of several prepared crops.
+func (c *Classifier) PredictBatch(faces [][]float32) ([][]float32, error) {
+	out := make([][]float32, 0, len(faces))
+	faceSize := 3 * c.inputH * c.inputW
+	k := len(c.labels)
+
+	for len(faces) > 0 {
+		n := min(len(faces), c.maxBatch)
+		for i, f := range faces[:n] {
+			copy(c.inBuf[i*faceSize:(i+1)*faceSize], f)
+		}
+
+		err := runBatch(c.session,
+			c.inBuf[:n*faceSize], ort.NewShape(int64(n), 3, int64(c.inputH), int64(c.inputW)),
+			c.outBuf[:n*k], ort.NewShape(int64(n), int64(k)))
+		if err != nil {
+			return nil, fmt.Errorf("run %s classifier: %w", c.name, err)
+		}
+
+		for i := 0; i < n; i++ {
+			out = append(out, softmax(c.outBuf[i*k:(i+1)*k]))
+		}
+		faces = faces[n:]
+	}
+	return out, nil
+}
+
+func (c *Classifier) preprocess(img image.Image, bbox [4]float32) []float32 {
+	return contextInput(img, bbox, c.cropScale, c.inputW, c.inputH, c.mean, c.std, c.bgr)
+}
+
+// fold adds one crop's label probabilities, weighted by the crop's quality, to
+// the track's running sums and updates the track's smoothed attribute.
+func (c *Classifier) fold(track *Track, probs []float32, weight float32) {
+	if track.labelSums == nil {
+		track.labelSums = make(map[string][]float64)
+	}
+	sums := track.labelSums[c.name]
+	if sums == nil {
+		sums = make([]float64, len(c.labels))
+		track.labelSums[c.name] = sums
+	}
+
+	w := max(float64(weight), 0.01)
+	var total float64
+	best := 0
+	for i, p := range probs {
+		sums[i] += w * float64(p)
+		total += sums[i]
+		if sums[i] > sums[best] {
+			best = i
+		}
+	}
+
+	if track.FaceAttrs == nil {
+		track.FaceAttrs = make(models.FaceAttributes)
+	}
+	label := c.labels[best]
+	track.FaceAttrs[c.name] = models.FaceAttribute{
+		Label:      label,
+		Confidence: float32(sums[best] / total),
+		Occluding:  c.penalty[label] > 0,
+	}
+}
+
+// occlusionFactor returns the factor a track's match scores are reported with:
+// the product of 1 - penalty over the occluding labels the track has.
+func occlusionFactor(classifiers []*Classifier, track *Track) float32 {
+	factor := float32(1)
+	for _, c := range classifiers {
+		if a, ok := track.FaceAttrs[c.name]; ok {
+			factor *= 1 - c.penalty[a.Label]
+		}
+	}
+	return factor
+}
+
+func (c *Classifier) Close() {
+	if c.session != nil {
+		c.session.Destroy()
+	}
+}
@@ -3,6 +3,7 @@ package vision
 import (
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 
 	ort "github.com/yalue/onnxruntime_go"
@@ -15,26 +16,57 @@ type Detection struct {
 	Landmarks  [5][2]float32 // 5 facial landmarks (eyes, nose, mouth corners)
 }
 
-// Detector runs RetinaFace face detection using ONNX Runtime.
-type Detector struct {
-	session       *ort.AdvancedSession
-	inputTensor   *ort.Tensor[float32]
-	outputTensors []*ort.Tensor[float32]
-	threshold     float32
-	inputW        int
-	inputH        int
+// InputTransform maps detector input coordinates back to the original frame:
+// orig = (input - Pad) * Scale. Stretch mode has zero padding and per-axis scales;
+// letterbox mode has a single scale and centres the frame with padding.
+type InputTransform struct {
+	ScaleX, ScaleY float32
+	PadX, PadY     float32
+	OrigW, OrigH   int
 }
 
-// stride configuration for RetinaFace det_10g
-var strides = []int{8, 16, 32}
+// toOriginal maps a point of the detector input back to the original frame.
+func (tr InputTransform) toOriginal(x, y float32) (float32, float32) {
+	return (x - tr.PadX) * tr.ScaleX, (y - tr.PadY) * tr.ScaleY
+}
 
-// anchorsPerStride is the number of anchors per pixel at each stride
-const anchorsPerStride = 2
+// Detector resize modes (VisionConfig.DetectorResize).
+const (
+	ResizeStretch   = "stretch"
+	ResizeLetterbox = "letterbox"
+)
 
-// NewDetector loads the RetinaFace ONNX model.
+// Detector runs RetinaFace / SCRFD face detection using ONNX Runtime.
+type Detector struct {
+	session          *ort.AdvancedSession
+	inputTensor      *ort.Tensor[float32]
+	outputTensors    []*ort.Tensor[float32]
+	inputW           int
+	inputH           int
+	letterbox        bool
+	strides          []int
+	anchorsPerStride int
+	mean, std        [3]float32
+}
+
+// NewDetector loads an anchor-based face detector described by spec
+// (RetinaFace det_10g and the SCRFD family share the output layout).
+// inputSize is the square model input side (e.g. 320, 640, 1280) and must be a
+// multiple of the largest stride; a fixed spec input_size takes precedence.
+// resize selects how frames are fitted to it: ResizeStretch or ResizeLetterbox.
 // opts may be nil (ORT defaults) or a pre-configured *ort.SessionOptions.
-func NewDetector(modelPath string, threshold float32, opts *ort.SessionOptions) (*Detector, error) {
-	inputW, inputH := 640, 640
+func NewDetector(spec ModelSpec, inputSize int, resize string, opts *ort.SessionOptions) (*Detector, error) {
+	if spec.Size[0] > 0 {
+		inputSize = spec.Size[0]
+	}
+	maxStride := slices.Max(spec.Strides)
+	if inputSize <= 0 || inputSize%maxStride != 0 {
+		return nil, fmt.Errorf("detector input size %d must be a positive multiple of %d", inputSize, maxStride)
+	}
+	if resize != ResizeStretch && resize != ResizeLetterbox {
+		return nil, fmt.Errorf("unknown detector resize mode %q", resize)
+	}
+	inputW, inputH := inputSize, inputSize
 
 	inputShape := ort.NewShape(1, 3, int64(inputH), int64(inputW))
 	inputTensor, err := ort.NewEmptyTensor[float32](inputShape)
@@ -42,7 +74,8 @@ func NewDetector(modelPath string, threshold float32, opts *ort.SessionOptions)
 		return nil, fmt.Errorf("create input tensor: %w", err)
 	}
 
-	// det_10g output shapes (NO batch dimension):
+	// det_10g output shapes (NO batch dimension), anchors per stride = (size/stride)^2 * 2.
+	// For a 640 input:
 	// scores:    [12800,1] [3200,1] [800,1]     -> stride 8, 16, 32
 	// bboxes:    [12800,4] [3200,4] [800,4]     -> stride 8, 16, 32
 	// landmarks: [12800,10] [3200,10] [800,10]  -> stride 8, 16, 32
@@ -50,22 +83,27 @@ func NewDetector(modelPath string, threshold float32, opts *ort.SessionOptions)
 	// 12800 = (640/8)*(640/8)*2   = 80*80*2
 	// 3200  = (640/16)*(640/16)*2 = 40*40*2
 	// 800   = (640/32)*(640/32)*2 = 20*20*2
+	//
+	// spec.Outputs lists the scores, then the bboxes, then the landmarks
+	// outputs, each group ordered like spec.Strides.
+	anchors := func(stride int) int64 {
+		return int64((inputW / stride) * (inputH / stride) * spec.AnchorsPerStride)
+	}
 
 	type outputSpec struct {
 		name  string
 		shape ort.Shape
 	}
 
-	outputs := []outputSpec{
-		{"448", ort.NewShape(12800, 1)},  // scores stride 8
-		{"471", ort.NewShape(3200, 1)},   // scores stride 16
-		{"494", ort.NewShape(800, 1)},    // scores stride 32
-		{"451", ort.NewShape(12800, 4)},  // bboxes stride 8
-		{"474", ort.NewShape(3200, 4)},   // bboxes stride 16
-		{"497", ort.NewShape(800, 4)},    // bboxes stride 32
-		{"454", ort.NewShape(12800, 10)}, // landmarks stride 8
-		{"477", ort.NewShape(3200, 10)},  // landmarks stride 16
-		{"500", ort.NewShape(800, 10)},   // landmarks stride 32
+	groupWidths := []int64{1, 4, 10} // scores, bboxes, landmarks
+	outputs := make([]outputSpec, len(spec.Outputs))
+	for i, name := range spec.Outputs {
+		n, k := anchors(spec.Strides[i%len(spec.Strides)]), groupWidths[i/len(spec.Strides)]
+		shape := ort.NewShape(n, k)
+		if spec.OutputBatchDim {
+			shape = ort.NewShape(1, n, k)
+		}
+		outputs[i] = outputSpec{name, shape}
 	}
 
 	outputNames := make([]string, len(outputs))
@@ -87,8 +125,8 @@ func NewDetector(modelPath string, threshold float32, opts *ort.SessionOptions)
 		outputValues[i] = t
 	}
 
-	session, err := ort.NewAdvancedSession(modelPath,
-		[]string{"input.1"},
+	session, err := ort.NewAdvancedSession(spec.File,
+		[]string{spec.Input},
 		outputNames,
 		[]ort.Value{inputTensor},
 		outputValues,
@@ -103,19 +141,47 @@ func NewDetector(modelPath string, threshold float32, opts *ort.SessionOptions)
 	}
 
 	return &Detector{
-		session:       session,
-		inputTensor:   inputTensor,
-		outputTensors: outputTensors,
-		threshold:     threshold,
-		inputW:        inputW,
-		inputH:        inputH,
+		session:          session,
+		inputTensor:      inputTensor,
+		outputTensors:    outputTensors,
+		inputW:           inputW,
+		inputH:           inputH,
+		letterbox:        resize == ResizeLetterbox,
+		strides:          spec.Strides,
+		anchorsPerStride: spec.AnchorsPerStride,
+		mean:             spec.Mean,
+		std:              spec.Std,
 	}, nil
 }
 
-// Detect runs face detection on a preprocessed image.
-// imgData should be CHW format [3, inputH, inputW], normalized.
-// origW/origH are the original image dimensions for coordinate scaling.
-func (d *Detector) Detect(imgData []float32, origW, origH int) ([]Detection, error) {
+// Transform returns how a frame of origW×origH is placed into the detector input.
+func (d *Detector) Transform(origW, origH int) InputTransform {
+	if !d.letterbox {
+		return InputTransform{
+			ScaleX: float32(origW) / float32(d.inputW),
+			ScaleY: float32(origH) / float32(d.inputH),
+			OrigW:  origW,
+			OrigH:  origH,
+		}
+	}
+
+	scale := float32(math.Max(float64(origW)/float64(d.inputW), float64(origH)/float64(d.inputH)))
+	newW := int(math.Round(float64(float32(origW) / scale)))
+	newH := int(math.Round(float64(float32(origH) / scale)))
+	return InputTransform{
+		ScaleX: scale,
+		ScaleY: scale,
+		PadX:   float32((d.inputW - newW) / 2),
+		PadY:   float32((d.inputH - newH) / 2),
+		OrigW:  origW,
+		OrigH:  origH,
+	}
+}
+
+// Detect runs face detection on a preprocessed image and keeps faces scoring at least threshold.
+// imgData should be CHW format [3, inputH, inputW], normalized, laid out according to tr.
+// tr maps input coordinates back to the original image.
+func (d *Detector) Detect(imgData []float32, tr InputTransform, threshold float32) ([]Detection, error) {
 	inputSlice := d.inputTensor.GetData()
 	copy(inputSlice, imgData)
 
@@ -123,23 +189,23 @@ func (d *Detector) Detect(imgData []float32, origW, origH int) ([]Detection, err
 		return nil, fmt.Errorf("run detection: %w", err)
 	}
 
-	detections := d.parseDetections(origW, origH)
+	detections := d.parseDetections(tr, threshold)
 	detections = nms(detections, 0.4)
 
 	return detections, nil
 }
 
-// parseDetections decodes anchor-based RetinaFace outputs at strides 8, 16, 32.
-func (d *Detector) parseDetections(origW, origH int) []Detection {
+// parseDetections decodes the anchor-based outputs at every stride of the model.
+func (d *Detector) parseDetections(tr InputTransform, threshold float32) []Detection {
 	var detections []Detection
 
-	scaleW := float32(origW) / float32(d.inputW)
-	scaleH := float32(origH) / float32(d.inputH)
+	origW, origH := tr.OrigW, tr.OrigH
 
-	for si, stride := range strides {
-		scores := d.outputTensors[si].GetData()       // [N, 1]
-		bboxes := d.outputTensors[si+3].GetData()     // [N, 4]
-		landmarks := d.outputTensors[si+6].GetData()  // [N, 10]
+	ns := len(d.strides)
+	for si, stride := range d.strides {
+		scores := d.outputTensors[si].GetData()         // [N, 1]
+		bboxes := d.outputTensors[si+ns].GetData()      // [N, 4]
+		landmarks := d.outputTensors[si+2*ns].GetData() // [N, 10]
 
 		fmW := d.inputW / stride
 		fmH := d.inputH / stride
@@ -147,10 +213,10 @@ func (d *Detector) parseDetections(origW, origH int) []Detection {
 		idx := 0
 		for cy := 0; cy < fmH; cy++ {
 			for cx := 0; cx < fmW; cx++ {
-				for a := 0; a < anchorsPerStride; a++ {
+				for a := 0; a < d.anchorsPerStride; a++ {
 					score := scores[idx]
 
-					if score >= d.threshold {
+					if score >= threshold {
 						// Anchor center
 						anchorX := float32(cx) * float32(stride)
 						anchorY := float32(cy) * float32(stride)
@@ -158,10 +224,8 @@ func (d *Detector) parseDetections(origW, origH int) []Detection {
 						// Decode bbox: distance from anchor to edges
 						// Model outputs normalized distances – multiply by stride for pixel scale
 						st := float32(stride)
-						x1 := (anchorX - bboxes[idx*4+0]*st) * scaleW
-						y1 := (anchorY - bboxes[idx*4+1]*st) * scaleH
-						x2 := (anchorX + bboxes[idx*4+2]*st) * scaleW
-						y2 := (anchorY + bboxes[idx*4+3]*st) * scaleH
+						x1, y1 := tr.toOriginal(anchorX-bboxes[idx*4+0]*st, anchorY-bboxes[idx*4+1]*st)
+						x2, y2 := tr.toOriginal(anchorX+bboxes[idx*4+2]*st, anchorY+bboxes[idx*4+3]*st)
 
 						// Clamp to image bounds
 						x1 = clampF(x1, 0, float32(origW))
@@ -172,8 +236,7 @@ func (d *Detector) parseDetections(origW, origH int) []Detection {
 						// Decode landmarks
 						var lm [5][2]float32
 						for li := 0; li < 5; li++ {
-							lm[li][0] = (anchorX + landmarks[idx*10+li*2]*st) * scaleW
-							lm[li][1] = (anchorY + landmarks[idx*10+li*2+1]*st) * scaleH
+							lm[li][0], lm[li][1] = tr.toOriginal(anchorX+landmarks[idx*10+li*2]*st, anchorY+landmarks[idx*10+li*2+1]*st)
 						}
 
 						detections = append(detections, Detection{
@@ -0,0 +1,107 @@
+package vision
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestDetectorTransformRoundTrip(t *testing.T) {
+	const input = 320
+	tests := []struct {
+		name      string
+		letterbox bool
+		w, h      int
+		face      image.Rectangle // in the frame
+	}{
+		{name: "stretch landscape", w: 640, h: 360, face: image.Rect(200, 100, 280, 200)},
+		{name: "stretch portrait", w: 300, h: 500, face: image.Rect(40, 300, 120, 420)},
+		{name: "letterbox landscape", letterbox: true, w: 640, h: 360, face: image.Rect(200, 100, 280, 200)},
+		{name: "letterbox portrait", letterbox: true, w: 300, h: 500, face: image.Rect(40, 300, 120, 420)},
+		{name: "letterbox square", letterbox: true, w: 480, h: 480, face: image.Rect(0, 0, 96, 96)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Detector{
+				inputW: input, inputH: input, letterbox: tt.letterbox,
+				mean: [3]float32{127.5, 127.5, 127.5}, std: [3]float32{128, 128, 128},
+			}
+			tr := d.Transform(tt.w, tt.h)
+			if tt.letterbox && tr.ScaleX != tr.ScaleY {
+				t.Fatalf("letterbox scales %v and %v differ", tr.ScaleX, tr.ScaleY)
+			}
+
+			// A white face on a black frame, located in the detector input
+			img := image.NewRGBA(image.Rect(0, 0, tt.w, tt.h))
+			for y := tt.face.Min.Y; y < tt.face.Max.Y; y++ {
+				for x := tt.face.Min.X; x < tt.face.Max.X; x++ {
+					img.Set(x, y, color.White)
+				}
+			}
+			data := d.preprocess(img, tr)
+			found := image.Rectangle{}
+			for y := 0; y < input; y++ {
+				for x := 0; x < input; x++ {
+					if data[y*input+x] > 0 {
+						found = found.Union(image.Rect(x, y, x+1, y+1))
+					}
+				}
+			}
+
+			// Mapping it back as parseDetections does recovers the face
+			x1, y1 := tr.toOriginal(float32(found.Min.X), float32(found.Min.Y))
+			x2, y2 := tr.toOriginal(float32(found.Max.X), float32(found.Max.Y))
+			got := [4]float32{x1, y1, x2, y2}
+			want := [4]float32{float32(tt.face.Min.X), float32(tt.face.Min.Y), float32(tt.face.Max.X), float32(tt.face.Max.Y)}
+			for k := range got {
+				tol := 2 * tr.ScaleX
+				if k%2 == 1 {
+					tol = 2 * tr.ScaleY
+				}
+				if math.Abs(float64(got[k]-want[k])) > float64(tol) {
+					t.Fatalf("face maps back to %v, want %v", got, want)
+				}
+			}
+
+			// Landmarks: frame → input → frame
+			for _, p := range [][2]float32{{0, 0}, {float32(tt.w) / 2, float32(tt.h) / 3}, {float32(tt.w), float32(tt.h)}} {
+				ix, iy := p[0]/tr.ScaleX+tr.PadX, p[1]/tr.ScaleY+tr.PadY
+				if ix < 0 || iy < 0 || ix > input || iy > input {
+					t.Errorf("point %v lies outside the input at (%v, %v)", p, ix, iy)
+				}
+				if x, y := tr.toOriginal(ix, iy); math.Abs(float64(x-p[0])) > 1e-3 || math.Abs(float64(y-p[1])) > 1e-3 {
+					t.Errorf("point %v maps back to (%v, %v)", p, x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectorLetterboxPadding(t *testing.T) {
+	d := &Detector{
+		inputW: 320, inputH: 320, letterbox: true,
+		mean: [3]float32{127.5, 127.5, 127.5}, std: [3]float32{128, 128, 128},
+	}
+	tr := d.Transform(640, 320) // half the input height is padding
+	if tr.PadX != 0 || tr.PadY != 80 || tr.ScaleX != 2 {
+		t.Fatalf("transform = %+v, want scale 2 and 80 rows of padding above and below", tr)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 640, 320))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	data := d.preprocess(img, tr)
+	black := -127.5 / float32(128)
+	for _, y := range []int{0, 79, 240, 319} {
+		if v := data[y*320+160]; v != black {
+			t.Errorf("padding row %d = %v, want black (%v)", y, v, black)
+		}
+	}
+	for _, y := range []int{80, 160, 239} {
+		if v := data[y*320+160]; v <= 0 {
+			t.Errorf("frame row %d = %v, want white", y, v)
+		}
+	}
+}
@@ -7,40 +7,33 @@ import (
 	ort "github.com/yalue/onnxruntime_go"
 )
 
-// Embedder extracts face embeddings using ArcFace ONNX model.
+// Embedder extracts face embeddings using an ArcFace-style ONNX model.
+// The session has a dynamic batch dimension, so several faces can share one run.
 type Embedder struct {
-	session      *ort.AdvancedSession
-	inputTensor  *ort.Tensor[float32]
-	outputTensor *ort.Tensor[float32]
-	inputW       int
-	inputH       int
-	embDim       int
+	session   *ort.DynamicAdvancedSession
+	inputW    int
+	inputH    int
+	embDim    int
+	maxBatch  int
+	version   string
+	mean, std [3]float32
+	inBuf     []float32 // reused input buffer, maxBatch faces
+	outBuf    []float32 // reused output buffer, maxBatch embeddings
 }
 
-// NewEmbedder loads the ArcFace ONNX model for face embedding extraction.
+// NewEmbedder loads the face embedding model described by spec.
+// maxBatch caps the number of faces per session run (<1 = 1).
 // opts may be nil (ORT defaults) or a pre-configured *ort.SessionOptions.
-func NewEmbedder(modelPath string, opts *ort.SessionOptions) (*Embedder, error) {
-	// ArcFace w600k_r50 expects 112x112 input
-	inputW, inputH := 112, 112
-	embDim := 512
-
-	inputShape := ort.NewShape(1, 3, int64(inputH), int64(inputW))
-	inputTensor, err := ort.NewEmptyTensor[float32](inputShape)
-	if err != nil {
-		return nil, fmt.Errorf("create input tensor: %w", err)
-	}
-
-	outputShape := ort.NewShape(1, int64(embDim))
-	outputTensor, err := ort.NewEmptyTensor[float32](outputShape)
-	if err != nil {
-		return nil, fmt.Errorf("create output tensor: %w", err)
+func NewEmbedder(spec ModelSpec, maxBatch int, opts *ort.SessionOptions) (*Embedder, error) {
+	inputW, inputH := spec.Size[0], spec.Size[1]
+	embDim := spec.EmbeddingDim
+	if maxBatch < 1 {
+		maxBatch = 1
 	}
 
-	session, err := ort.NewAdvancedSession(modelPath,
-		[]string{"input.1"},
-		[]string{"683"},
-		[]ort.Value{inputTensor},
-		[]ort.Value{outputTensor},
+	session, err := ort.NewDynamicAdvancedSession(spec.File,
+		[]string{spec.Input},
+		spec.Outputs,
 		opts,
 	)
 	if err != nil {
@@ -48,37 +41,77 @@ func NewEmbedder(modelPath string, opts *ort.SessionOptions) (*Embedder, error)
 	}
 
 	return &Embedder{
-		session:      session,
-		inputTensor:  inputTensor,
-		outputTensor: outputTensor,
-		inputW:       inputW,
-		inputH:       inputH,
-		embDim:       embDim,
+		session:  session,
+		inputW:   inputW,
+		inputH:   inputH,
+		embDim:   embDim,
+		maxBatch: maxBatch,
+		version:  spec.Version,
+		mean:     spec.Mean,
+		std:      spec.Std,
+		inBuf:    make([]float32, maxBatch*3*inputH*inputW),
+		outBuf:   make([]float32, maxBatch*embDim),
 	}, nil
 }
 
 // Extract runs embedding extraction on a face crop.
-// faceData should be CHW format [3, 112, 112], normalized.
-// Returns a normalized 512-dimensional embedding vector.
+// faceData should be CHW format [3, inputH, inputW], normalized.
+// Returns an L2-normalized embedding of EmbeddingDim values.
 func (e *Embedder) Extract(faceData []float32) ([]float32, error) {
-	// Copy input data into the input tensor
-	inputSlice := e.inputTensor.GetData()
-	copy(inputSlice, faceData)
-
-	if err := e.session.Run(); err != nil {
-		return nil, fmt.Errorf("run embedding: %w", err)
+	out, err := e.ExtractBatch([][]float32{faceData})
+	if err != nil {
+		return nil, err
 	}
+	return out[0], nil
+}
 
-	// Read output directly from the output tensor
-	outputData := e.outputTensor.GetData()
+// ExtractBatch runs embedding extraction on several face crops (each CHW
+// [3, inputH, inputW], normalized) in as few session runs as maxBatch allows.
+// Returns one normalized embedding per face, in order.
+func (e *Embedder) ExtractBatch(faces [][]float32) ([][]float32, error) {
+	out := make([][]float32, 0, len(faces))
+	faceSize := 3 * e.inputH * e.inputW
+
+	for len(faces) > 0 {
+		n := min(len(faces), e.maxBatch)
+		for i, f := range faces[:n] {
+			copy(e.inBuf[i*faceSize:(i+1)*faceSize], f)
+		}
 
-	embedding := make([]float32, e.embDim)
-	copy(embedding, outputData)
+		err := runBatch(e.session,
+			e.inBuf[:n*faceSize], ort.NewShape(int64(n), 3, int64(e.inputH), int64(e.inputW)),
+			e.outBuf[:n*e.embDim], ort.NewShape(int64(n), int64(e.embDim)))
+		if err != nil {
+			return nil, fmt.Errorf("run embedding: %w", err)
+		}
 
-	// L2 normalize
-	normalize(embedding)
+		for i := 0; i < n; i++ {
+			embedding := make([]float32, e.embDim)
+			copy(embedding, e.outBuf[i*e.embDim:(i+1)*e.embDim])
+			normalize(embedding)
+			out = append(out, embedding)
+		}
+		faces = faces[n:]
+	}
+	return out, nil
+}
+
+// runBatch wraps the caller's buffers in tensors of the given shapes and runs
+// the session once. ORT reads and writes the Go buffers directly.
+func runBatch(session *ort.DynamicAdvancedSession, in []float32, inShape ort.Shape, out []float32, outShape ort.Shape) error {
+	input, err := ort.NewTensor(inShape, in)
+	if err != nil {
+		return fmt.Errorf("create input tensor: %w", err)
+	}
+	defer input.Destroy()
 
-	return embedding, nil
+	output, err := ort.NewTensor(outShape, out)
+	if err != nil {
+		return fmt.Errorf("create output tensor: %w", err)
+	}
+	defer output.Destroy()
+
+	return session.Run([]ort.Value{input}, []ort.Value{output})
 }
 
 // InputSize returns the expected face crop dimensions.
@@ -91,16 +124,15 @@ func (e *Embedder) EmbeddingDim() int {
 	return e.embDim
 }
 
+// Version returns the model version recorded with the embeddings it produces.
+func (e *Embedder) Version() string {
+	return e.version
+}
+
 func (e *Embedder) Close() {
 	if e.session != nil {
 		e.session.Destroy()
 	}
-	if e.inputTensor != nil {
-		e.inputTensor.Destroy()
-	}
-	if e.outputTensor != nil {
-		e.outputTensor.Destroy()
-	}
 }
 
 // normalize performs L2 normalization in-place.
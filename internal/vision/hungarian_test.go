@@ -0,0 +1,46 @@
+package vision
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHungarian(t *testing.T) {
+	tests := []struct {
+		name string
+		cost [][]float64
+		want []int
+	}{
+		{name: "empty", cost: nil, want: nil},
+		{name: "no columns", cost: [][]float64{{}, {}}, want: []int{-1, -1}},
+		{name: "identity", cost: [][]float64{{0, 1}, {1, 0}}, want: []int{0, 1}},
+		{name: "swapped", cost: [][]float64{{1, 0}, {0, 1}}, want: []int{1, 0}},
+		{
+			// Taking the cheapest pair first (row 0 → column 0) costs 1+100;
+			// the optimum is 2+2.
+			name: "greedy is not optimal",
+			cost: [][]float64{{1, 2}, {2, 100}},
+			want: []int{1, 0},
+		},
+		{
+			name: "classic 4x4",
+			cost: [][]float64{
+				{9, 2, 7, 8},
+				{6, 4, 3, 7},
+				{5, 8, 1, 8},
+				{7, 6, 9, 4},
+			},
+			want: []int{1, 0, 2, 3}, // 2+6+1+4 = 13
+		},
+		{name: "more columns", cost: [][]float64{{5, 1, 3}, {1, 2, 3}}, want: []int{1, 0}},
+		{name: "more rows", cost: [][]float64{{5, 1}, {1, 2}, {0.5, 0.6}}, want: []int{1, -1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hungarian(tt.cost)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("hungarian = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package vision
+
+// identityVote is one recognition result of a track: the matched person, or
+// "" for unknown, weighted by its match score.
+type identityVote struct {
+	personID string
+	weight   float32
+}
+
+type identityTally struct {
+	weight   float32
+	votes    int
+	scoreSum float32 // match scores only, for the consolidated score
+}
+
+// VoteIdentity records one recognition pass for track and re-evaluates its
+// consolidated identity (track.PersonID / track.MatchScore) over the last
+// identity_window passes.
+//
+// personID is the instantaneous top match, or "" if nothing passed the
+// recognition threshold; weight is its match score, or for unknown results the
+// weight a miss carries (callers use the recognition threshold).
+//
+// The identity with the largest summed weight leads the vote; ties go to the
+// current identity, then to more votes, then to the smaller ID. It is adopted only
+// with at least identity_min_votes votes, and a person already assigned to the
+// track is only replaced (or dropped back to unknown) when the leader's share of
+// the total weight beats the person's share by identity_switch_margin.
+// Returns true if track.PersonID changed.
+func (t *Tracker) VoteIdentity(track *Track, personID string, weight float32) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	track.votes = append(track.votes, identityVote{personID: personID, weight: weight})
+	if len(track.votes) > t.identityWindow {
+		track.votes = append(track.votes[:0], track.votes[len(track.votes)-t.identityWindow:]...)
+	}
+
+	tally := make(map[string]*identityTally)
+	var total float32
+	for _, v := range track.votes {
+		tl := tally[v.personID]
+		if tl == nil {
+			tl = &identityTally{}
+			tally[v.personID] = tl
+		}
+		tl.weight += v.weight
+		tl.votes++
+		if v.personID != "" {
+			tl.scoreSum += v.weight
+		}
+		total += v.weight
+	}
+	if total <= 0 {
+		return false
+	}
+
+	// Ties go to the current identity, so equal evidence never causes a switch,
+	// and are otherwise broken the same way whatever the map order.
+	beats := func(id, other string) bool {
+		tl, ot := tally[id], tally[other]
+		switch {
+		case ot == nil:
+			return true
+		case tl.weight != ot.weight:
+			return tl.weight > ot.weight
+		case id == track.PersonID || other == track.PersonID:
+			return id == track.PersonID
+		case tl.votes != ot.votes:
+			return tl.votes > ot.votes
+		}
+		return id < other
+	}
+	leader := track.PersonID
+	for id := range tally {
+		if id != leader && beats(id, leader) {
+			leader = id
+		}
+	}
+
+	lt := tally[leader]
+	if leader == track.PersonID {
+		if leader != "" {
+			track.MatchScore = lt.scoreSum / float32(lt.votes)
+		}
+		return false
+	}
+	if lt.votes < t.identityMinVotes {
+		return false
+	}
+
+	var currentShare float32
+	if ct := tally[track.PersonID]; ct != nil {
+		currentShare = ct.weight / total
+	}
+	// Leaving unknown only needs the leader to outweigh it; replacing or dropping
+	// a person needs a clear margin.
+	var margin float32
+	if track.PersonID != "" {
+		margin = t.identitySwitchMargin
+	}
+	if lt.weight/total-currentShare <= margin {
+		return false
+	}
+
+	track.PersonID = leader
+	track.MatchScore = 0
+	if leader != "" {
+		track.MatchScore = lt.scoreSum / float32(lt.votes)
+	}
+	return true
+}
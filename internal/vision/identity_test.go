@@ -0,0 +1,74 @@
+package vision
+
+import (
+	"testing"
+
+	"github.com/your-org/fd/internal/config"
+)
+
+func TestVoteIdentity(t *testing.T) {
+	type vote struct {
+		personID string
+		weight   float32
+	}
+	repeat := func(personID string, weight float32, n int) []vote {
+		votes := make([]vote, n)
+		for i := range votes {
+			votes[i] = vote{personID, weight}
+		}
+		return votes
+	}
+	seq := func(parts ...[]vote) []vote {
+		var votes []vote
+		for _, p := range parts {
+			votes = append(votes, p...)
+		}
+		return votes
+	}
+
+	tests := []struct {
+		name     string
+		window   int
+		minVotes int
+		margin   float32
+		votes    []vote
+		want     string
+	}{
+		{name: "below min_votes", window: 5, minVotes: 2, votes: repeat("a", 0.8, 1), want: ""},
+		{name: "min_votes reached", window: 5, minVotes: 2, votes: repeat("a", 0.8, 2), want: "a"},
+		{name: "leaving unknown needs no margin", window: 5, minVotes: 1, margin: 0.5,
+			votes: []vote{{"", 0.4}, {"a", 0.5}}, want: "a"},
+		{name: "switch within margin", window: 10, minVotes: 2, margin: 0.25,
+			votes: seq(repeat("a", 0.8, 2), repeat("b", 0.8, 3)), want: "a"},
+		{name: "switch beyond margin", window: 10, minVotes: 2, margin: 0.25,
+			votes: seq(repeat("a", 0.8, 2), repeat("b", 0.8, 4)), want: "b"},
+		{name: "unknown within margin", window: 10, minVotes: 2, margin: 0.25,
+			votes: seq(repeat("a", 0.8, 2), repeat("", 0.4, 6)), want: "a"},
+		{name: "back to unknown", window: 10, minVotes: 2, margin: 0.25,
+			votes: seq(repeat("a", 0.8, 2), repeat("", 0.4, 8)), want: ""},
+		{name: "window evicts old votes", window: 3, minVotes: 2, margin: 0.25,
+			votes: seq(repeat("a", 0.8, 2), repeat("b", 0.8, 3)), want: "b"},
+		{name: "tie keeps the current person", window: 5, minVotes: 1,
+			votes: []vote{{"a", 0.8}, {"b", 0.8}}, want: "a"},
+		{name: "tie goes to more votes", window: 5, minVotes: 2,
+			votes: []vote{{"b", 0.8}, {"a", 0.4}, {"a", 0.4}}, want: "a"},
+		{name: "full tie goes to the smaller ID", window: 7, minVotes: 2,
+			votes: seq(repeat("", 0.4, 3), []vote{{"b", 0.5}, {"a", 0.5}, {"b", 0.5}, {"a", 0.5}, {"", 0.1}}), want: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map order is random: every run must agree
+			for run := 0; run < 20; run++ {
+				tr := NewTracker("s", config.TrackingConfig{MaxAge: 5, MinHits: 1, IoUThreshold: 0.3, ReIDMaxDistance: -1,
+					IdentityWindow: tt.window, IdentityMinVotes: tt.minVotes, IdentitySwitchMargin: tt.margin})
+				track := &Track{}
+				for _, v := range tt.votes {
+					tr.VoteIdentity(track, v.personID, v.weight)
+				}
+				if track.PersonID != tt.want {
+					t.Fatalf("run %d: PersonID = %q, want %q", run, track.PersonID, tt.want)
+				}
+			}
+		})
+	}
+}
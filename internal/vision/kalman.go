@@ -0,0 +1,197 @@
+package vision
+
+import "math"
+
+// kalmanBoxFilter is the constant-velocity Kalman filter used by SORT.
+//
+// State:       [cx, cy, s, r, vcx, vcy, vs] — centre, area, aspect ratio (w/h) and their velocities.
+// Measurement: [cx, cy, s, r].
+// The aspect ratio is assumed constant, so it has no velocity term.
+type kalmanBoxFilter struct {
+	x [7]float64
+	p [7][7]float64
+}
+
+// SORT noise parameters (Bewley et al., 2016).
+var (
+	kalmanR = [4]float64{1, 1, 10, 10}
+	kalmanQ = [7]float64{1, 1, 1, 1, 0.01, 0.01, 0.0001}
+)
+
+func newKalmanBoxFilter(bbox [4]float32) *kalmanBoxFilter {
+	kf := &kalmanBoxFilter{}
+	z := bboxToMeasurement(bbox)
+	copy(kf.x[:4], z[:])
+
+	// High uncertainty for the unobserved initial velocities.
+	for i := 0; i < 4; i++ {
+		kf.p[i][i] = 10
+	}
+	for i := 4; i < 7; i++ {
+		kf.p[i][i] = 10000
+	}
+	return kf
+}
+
+// predict advances the state by one frame and returns the predicted bbox.
+func (kf *kalmanBoxFilter) predict() [4]float32 {
+	// Keep the predicted area positive.
+	if kf.x[2]+kf.x[6] <= 0 {
+		kf.x[6] = 0
+	}
+
+	// x = F x
+	kf.x[0] += kf.x[4]
+	kf.x[1] += kf.x[5]
+	kf.x[2] += kf.x[6]
+
+	// P = F P F^T + Q, where F = I with F[i][i+4] = 1 for i in 0..2.
+	var fp [7][7]float64
+	for i := 0; i < 7; i++ {
+		for j := 0; j < 7; j++ {
+			fp[i][j] = kf.p[i][j]
+			if i < 3 {
+				fp[i][j] += kf.p[i+4][j]
+			}
+		}
+	}
+	for i := 0; i < 7; i++ {
+		for j := 0; j < 7; j++ {
+			v := fp[i][j]
+			if j < 3 {
+				v += fp[i][j+4]
+			}
+			kf.p[i][j] = v
+		}
+		kf.p[i][i] += kalmanQ[i]
+	}
+
+	return kf.bbox()
+}
+
+// update corrects the state with an observed bbox.
+func (kf *kalmanBoxFilter) update(bbox [4]float32) {
+	z := bboxToMeasurement(bbox)
+
+	// H selects the first four state components, so H P H^T is the top-left 4x4 of P.
+	var s [4][4]float64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			s[i][j] = kf.p[i][j]
+		}
+		s[i][i] += kalmanR[i]
+	}
+	sInv, ok := invert4(s)
+	if !ok {
+		return
+	}
+
+	// K = P H^T S^-1 (7x4); P H^T is the first four columns of P.
+	var k [7][4]float64
+	for i := 0; i < 7; i++ {
+		for j := 0; j < 4; j++ {
+			var v float64
+			for m := 0; m < 4; m++ {
+				v += kf.p[i][m] * sInv[m][j]
+			}
+			k[i][j] = v
+		}
+	}
+
+	var y [4]float64
+	for i := 0; i < 4; i++ {
+		y[i] = z[i] - kf.x[i]
+	}
+	for i := 0; i < 7; i++ {
+		for j := 0; j < 4; j++ {
+			kf.x[i] += k[i][j] * y[j]
+		}
+	}
+
+	// P = (I - K H) P; K H only has non-zero entries in the first four columns.
+	var np [7][7]float64
+	for i := 0; i < 7; i++ {
+		for j := 0; j < 7; j++ {
+			v := kf.p[i][j]
+			for m := 0; m < 4; m++ {
+				v -= k[i][m] * kf.p[m][j]
+			}
+			np[i][j] = v
+		}
+	}
+	kf.p = np
+}
+
+// bbox returns the current state as x1, y1, x2, y2.
+func (kf *kalmanBoxFilter) bbox() [4]float32 {
+	s := math.Max(kf.x[2], 0)
+	r := kf.x[3]
+	if r <= 0 {
+		return [4]float32{float32(kf.x[0]), float32(kf.x[1]), float32(kf.x[0]), float32(kf.x[1])}
+	}
+	w := math.Sqrt(s * r)
+	h := s / math.Max(w, 1e-6)
+	return [4]float32{
+		float32(kf.x[0] - w/2),
+		float32(kf.x[1] - h/2),
+		float32(kf.x[0] + w/2),
+		float32(kf.x[1] + h/2),
+	}
+}
+
+func bboxToMeasurement(b [4]float32) [4]float64 {
+	w := float64(b[2] - b[0])
+	h := float64(b[3] - b[1])
+	r := 0.0
+	if h > 0 {
+		r = w / h
+	}
+	return [4]float64{
+		float64(b[0]) + w/2,
+		float64(b[1]) + h/2,
+		w * h,
+		r,
+	}
+}
+
+// invert4 inverts a 4x4 matrix with Gauss-Jordan elimination and partial pivoting.
+func invert4(a [4][4]float64) ([4][4]float64, bool) {
+	var inv [4][4]float64
+	for i := 0; i < 4; i++ {
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < 4; col++ {
+		pivot := col
+		for r := col + 1; r < 4; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return inv, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		d := a[col][col]
+		for j := 0; j < 4; j++ {
+			a[col][j] /= d
+			inv[col][j] /= d
+		}
+		for r := 0; r < 4; r++ {
+			if r == col {
+				continue
+			}
+			f := a[r][col]
+			if f == 0 {
+				continue
+			}
+			for j := 0; j < 4; j++ {
+				a[r][j] -= f * a[col][j]
+				inv[r][j] -= f * inv[col][j]
+			}
+		}
+	}
+	return inv, true
+}
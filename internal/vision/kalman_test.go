@@ -0,0 +1,46 @@
+package vision
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKalmanBoxFilterPredict(t *testing.T) {
+	tests := []struct {
+		name   string
+		vx, vy float32 // pixels per frame
+	}{
+		{name: "still", vx: 0, vy: 0},
+		{name: "moving right", vx: 8, vy: 0},
+		{name: "moving up and left", vx: -5, vy: -3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := func(i int) [4]float32 {
+				x, y := 100+tt.vx*float32(i), 100+tt.vy*float32(i)
+				return [4]float32{x, y, x + 40, y + 50}
+			}
+			kf := newKalmanBoxFilter(box(0))
+			for i := 1; i <= 20; i++ {
+				kf.predict()
+				kf.update(box(i))
+			}
+			got, want := kf.predict(), box(21)
+			for k := range got {
+				if math.Abs(float64(got[k]-want[k])) > 1 {
+					t.Fatalf("predicted %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestKalmanBoxFilterRoundTrip(t *testing.T) {
+	b := [4]float32{10, 20, 50, 70}
+	got := newKalmanBoxFilter(b).bbox()
+	for k := range got {
+		if math.Abs(float64(got[k]-b[k])) > 1e-3 {
+			t.Fatalf("bbox = %v, want %v", got, b)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package vision
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// LivenessDetector scores how likely a face crop shows a live face rather than
+// a photo, screen or mask, with a MiniFASNet-style classifier. The model sees
+// the face with its surroundings (the bbox scaled by crop_scale), where the
+// edges of a held-up phone or print are visible.
+type LivenessDetector struct {
+	session   *ort.DynamicAdvancedSession
+	inputW    int
+	inputH    int
+	maxBatch  int
+	mean, std [3]float32
+	cropScale float32
+	classes   int
+	liveClass int
+	bgr       bool
+	inBuf     []float32 // reused input buffer, maxBatch faces
+	outBuf    []float32 // reused output buffer, maxBatch × classes logits
+}
+
+// NewLivenessDetector loads the liveness model described by spec.
+// maxBatch caps the number of faces per session run (<1 = 1).
+// opts may be nil (ORT defaults) or a pre-configured *ort.SessionOptions.
+func NewLivenessDetector(spec ModelSpec, maxBatch int, opts *ort.SessionOptions) (*LivenessDetector, error) {
+	inputW, inputH := spec.Size[0], spec.Size[1]
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+
+	session, err := ort.NewDynamicAdvancedSession(spec.File,
+		[]string{spec.Input},
+		spec.Outputs,
+		opts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create liveness session: %w", err)
+	}
+
+	return &LivenessDetector{
+		session:   session,
+		inputW:    inputW,
+		inputH:    inputH,
+		maxBatch:  maxBatch,
+		mean:      spec.Mean,
+		std:       spec.Std,
+		cropScale: spec.CropScale,
+		classes:   spec.Classes,
+		liveClass: spec.LiveClass,
+		bgr:       spec.BGR,
+		inBuf:     make([]float32, maxBatch*3*inputH*inputW),
+		outBuf:    make([]float32, maxBatch*spec.Classes),
+	}, nil
+}
+
+// ScoreBatch returns the live-face probability (0..1) of several prepared crops.
+func (l *LivenessDetector) ScoreBatch(faces [][]float32) ([]float32, error) {
+	out := make([]float32, 0, len(faces))
+	faceSize := 3 * l.inputH * l.inputW
+
+	for len(faces) > 0 {
+		n := min(len(faces), l.maxBatch)
+		for i, f := range faces[:n] {
+			copy(l.inBuf[i*faceSize:(i+1)*faceSize], f)
+		}
+
+		err := runBatch(l.session,
+			l.inBuf[:n*faceSize], ort.NewShape(int64(n), 3, int64(l.inputH), int64(l.inputW)),
+			l.outBuf[:n*l.classes], ort.NewShape(int64(n), int64(l.classes)))
+		if err != nil {
+			return nil, fmt.Errorf("run liveness: %w", err)
+		}
+
+		for i := 0; i < n; i++ {
+			out = append(out, softmax(l.outBuf[i*l.classes : (i+1)*l.classes])[l.liveClass])
+		}
+		faces = faces[n:]
+	}
+	return out, nil
+}
+
+// preprocess crops the face with its context and converts it to the model input.
+func (l *LivenessDetector) preprocess(img image.Image, bbox [4]float32) []float32 {
+	return contextInput(img, bbox, l.cropScale, l.inputW, l.inputH, l.mean, l.std, l.bgr)
+}
+
+// contextInput returns the CHW model input for the bbox scaled by scale, nil
+// for a degenerate box.
+func contextInput(img image.Image, bbox [4]float32, scale float32, w, h int, mean, std [3]float32, bgr bool) []float32 {
+	crop := contextCrop(img, bbox, scale)
+	if crop == nil {
+		return nil
+	}
+	data := imageToFloat32CHW(crop, w, h, mean, std)
+	if bgr {
+		plane := w * h
+		r, b := data[:plane], data[2*plane:]
+		for i := range r {
+			r[i], b[i] = b[i], r[i]
+		}
+	}
+	return data
+}
+
+// contextCrop returns the bbox scaled by scale around its centre. The scale is
+// reduced to fit the frame and the box shifted inside it, as in MiniFASNet's
+// training crops.
+func contextCrop(img image.Image, bbox [4]float32, scale float32) image.Image {
+	bounds := img.Bounds()
+	imgW, imgH := float64(bounds.Dx()), float64(bounds.Dy())
+	boxW := float64(bbox[2] - bbox[0])
+	boxH := float64(bbox[3] - bbox[1])
+	if boxW <= 0 || boxH <= 0 {
+		return nil
+	}
+
+	s := math.Min(float64(scale), math.Min((imgW-1)/boxW, (imgH-1)/boxH))
+	w, h := boxW*s, boxH*s
+	cx := float64(bbox[0]+bbox[2]) / 2
+	cy := float64(bbox[1]+bbox[3]) / 2
+
+	x1 := math.Max(cx-w/2, 0)
+	y1 := math.Max(cy-h/2, 0)
+	x1 = math.Min(x1, imgW-w)
+	y1 = math.Min(y1, imgH-h)
+
+	rect := image.Rect(
+		bounds.Min.X+int(x1), bounds.Min.Y+int(y1),
+		bounds.Min.X+int(x1+w), bounds.Min.Y+int(y1+h),
+	).Intersect(bounds)
+	if rect.Empty() {
+		return nil
+	}
+
+	type subImager interface {
+		SubImage(r image.Rectangle) image.Image
+	}
+	if si, ok := img.(subImager); ok {
+		return si.SubImage(rect)
+	}
+	crop := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			crop.Set(x-rect.Min.X, y-rect.Min.Y, img.At(x, y))
+		}
+	}
+	return crop
+}
+
+// softmax returns the class probabilities of logits.
+func softmax(logits []float32) []float32 {
+	maxLogit := logits[0]
+	for _, v := range logits[1:] {
+		maxLogit = max(maxLogit, v)
+	}
+	probs := make([]float32, len(logits))
+	var sum float64
+	for i, v := range logits {
+		e := math.Exp(float64(v - maxLogit))
+		probs[i] = float32(e)
+		sum += e
+	}
+	for i := range probs {
+		probs[i] = float32(float64(probs[i]) / sum)
+	}
+	return probs
+}
+
+func (l *LivenessDetector) Close() {
+	if l.session != nil {
+		l.session.Destroy()
+	}
+}
@@ -0,0 +1,283 @@
+package vision
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Model roles in the manifest.
+const (
+	RoleDetector   = "detector"
+	RoleEmbedder   = "embedder"
+	RoleAttributes = "attributes"
+	RoleLiveness   = "liveness"   // optional anti-spoofing classifier
+	RoleClassifier = "classifier" // optional, any number: face attribute classifiers
+)
+
+// manifestFiles are looked up in models_dir in this order. JSON is valid YAML,
+// so all of them go through the same parser.
+var manifestFiles = []string{"manifest.yaml", "manifest.yml", "manifest.json"}
+
+// ModelSpec describes one ONNX model: where it is, how its tensors are named
+// and how its input is prepared.
+type ModelSpec struct {
+	Role    string     `yaml:"role" json:"role"`
+	File    string     `yaml:"file" json:"file"` // relative to models_dir
+	Input   string     `yaml:"input" json:"input"`
+	Outputs []string   `yaml:"outputs" json:"outputs"`
+	Size    [2]int     `yaml:"input_size" json:"input_size"` // width, height; detector: 0 = vision.detector_input_size
+	Mean    [3]float32 `yaml:"mean" json:"mean"`             // per RGB channel: x = (pixel - mean) / std
+	Std     [3]float32 `yaml:"std" json:"std"`
+
+	// Detector (RetinaFace / SCRFD anchor layout)
+	Strides          []int `yaml:"strides" json:"strides"`
+	AnchorsPerStride int   `yaml:"anchors_per_stride" json:"anchors_per_stride"`
+	OutputBatchDim   bool  `yaml:"output_batch_dim" json:"output_batch_dim"` // outputs are [1, N, k] instead of [N, k]
+
+	// Embedder. Version is recorded with every embedding; only vectors of the
+	// same version are compared. Defaults to the file name without extension.
+	EmbeddingDim int    `yaml:"embedding_dim" json:"embedding_dim"`
+	Version      string `yaml:"version" json:"version"`
+
+	// Attributes: output is [female_logit, male_logit, age / age_scale]
+	AgeScale float32 `yaml:"age_scale" json:"age_scale"`
+
+	// Liveness and classifiers: the input is the bbox scaled by crop_scale
+	// around its centre, so the model sees some context (phone edges, hats).
+	CropScale float32 `yaml:"crop_scale" json:"crop_scale"`
+	BGR       bool    `yaml:"bgr" json:"bgr"` // input channels in BGR order
+
+	// Liveness: the output holds one logit per class, live_class being the live face.
+	Classes   int `yaml:"classes" json:"classes"`
+	LiveClass int `yaml:"live_class" json:"live_class"`
+
+	// Classifier: name keys the attribute, labels name the output logits in
+	// order. Faces given a label listed in occlusion_penalty have their reported
+	// match scores multiplied by 1 - penalty.
+	Name             string             `yaml:"name" json:"name"`
+	Labels           []string           `yaml:"labels" json:"labels"`
+	OcclusionPenalty map[string]float32 `yaml:"occlusion_penalty" json:"occlusion_penalty"`
+}
+
+// Manifest lists the models the vision pipeline runs, one per role.
+type Manifest struct {
+	Models []ModelSpec `yaml:"models" json:"models"`
+
+	dir string
+}
+
+// DefaultManifest describes the InsightFace buffalo_l pack
+// (det_10g, w600k_r50, genderage) that scripts/download_models.sh installs,
+// plus the optional MiniFASNetV2 liveness model, used when its file is present.
+func DefaultManifest() Manifest {
+	return Manifest{Models: []ModelSpec{
+		{
+			Role:  RoleDetector,
+			File:  "det_10g.onnx",
+			Input: "input.1",
+			// scores, bboxes, landmarks, each for strides 8, 16, 32
+			Outputs:          []string{"448", "471", "494", "451", "474", "497", "454", "477", "500"},
+			Mean:             [3]float32{127.5, 127.5, 127.5},
+			Std:              [3]float32{128, 128, 128},
+			Strides:          []int{8, 16, 32},
+			AnchorsPerStride: 2,
+		},
+		{
+			Role:         RoleEmbedder,
+			File:         "w600k_r50.onnx",
+			Input:        "input.1",
+			Outputs:      []string{"683"},
+			Size:         [2]int{112, 112},
+			Mean:         [3]float32{127.5, 127.5, 127.5},
+			Std:          [3]float32{127.5, 127.5, 127.5},
+			EmbeddingDim: 512,
+		},
+		{
+			Role:     RoleAttributes,
+			File:     "genderage.onnx",
+			Input:    "data",
+			Outputs:  []string{"fc1"},
+			Size:     [2]int{96, 96},
+			Mean:     [3]float32{0, 0, 0},
+			Std:      [3]float32{1, 1, 1},
+			AgeScale: 100,
+		},
+		{
+			Role:      RoleLiveness,
+			File:      "2.7_80x80_MiniFASNetV2.onnx",
+			Input:     "input",
+			Outputs:   []string{"output"},
+			Size:      [2]int{80, 80},
+			Mean:      [3]float32{0, 0, 0},
+			Std:       [3]float32{1, 1, 1},
+			CropScale: 2.7,
+			Classes:   3,
+			LiveClass: 1,
+			BGR:       true,
+		},
+	}}
+}
+
+// LoadManifest reads the model manifest from dir, falling back to
+// DefaultManifest when dir has none.
+func LoadManifest(dir string) (Manifest, error) {
+	for _, name := range manifestFiles {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("read model manifest: %w", err)
+		}
+
+		var m Manifest
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return Manifest{}, fmt.Errorf("parse model manifest %s: %w", path, err)
+		}
+		m.dir = dir
+		if err := m.validate(); err != nil {
+			return Manifest{}, fmt.Errorf("model manifest %s: %w", path, err)
+		}
+		return m, nil
+	}
+
+	m := DefaultManifest()
+	m.dir = dir
+	return m, nil
+}
+
+// Model returns the spec for role with File resolved against the models directory.
+func (m Manifest) Model(role string) (ModelSpec, error) {
+	for _, spec := range m.Models {
+		if spec.Role == role {
+			return m.resolve(spec), nil
+		}
+	}
+	return ModelSpec{}, fmt.Errorf("no %s model in manifest", role)
+}
+
+// Classifiers returns the face attribute classifiers, files resolved.
+func (m Manifest) Classifiers() []ModelSpec {
+	var specs []ModelSpec
+	for _, spec := range m.Models {
+		if spec.Role == RoleClassifier {
+			specs = append(specs, m.resolve(spec))
+		}
+	}
+	return specs
+}
+
+func (m Manifest) resolve(spec ModelSpec) ModelSpec {
+	if spec.Version == "" {
+		spec.Version = strings.TrimSuffix(filepath.Base(spec.File), filepath.Ext(spec.File))
+	}
+	if !filepath.IsAbs(spec.File) {
+		spec.File = filepath.Join(m.dir, spec.File)
+	}
+	return spec
+}
+
+func (m Manifest) validate() error {
+	seen := make(map[string]bool)
+	for i, spec := range m.Models {
+		key := spec.Role
+		if spec.Role == RoleClassifier {
+			key += "/" + spec.Name
+		}
+		if seen[key] {
+			if spec.Role == RoleClassifier {
+				return fmt.Errorf("duplicate classifier %q", spec.Name)
+			}
+			return fmt.Errorf("duplicate %s model", spec.Role)
+		}
+		seen[key] = true
+		if err := spec.validate(); err != nil {
+			return fmt.Errorf("models[%d] (%s): %w", i, spec.Role, err)
+		}
+	}
+	for _, role := range []string{RoleDetector, RoleEmbedder, RoleAttributes} {
+		if !seen[role] {
+			return fmt.Errorf("no %s model", role)
+		}
+	}
+	return nil
+}
+
+func (s ModelSpec) validate() error {
+	if s.File == "" || s.Input == "" {
+		return fmt.Errorf("file and input are required")
+	}
+	for c, v := range s.Std {
+		if v == 0 {
+			return fmt.Errorf("std[%d] must be non-zero", c)
+		}
+	}
+
+	switch s.Role {
+	case RoleDetector:
+		if len(s.Strides) == 0 || s.AnchorsPerStride <= 0 {
+			return fmt.Errorf("strides and anchors_per_stride are required")
+		}
+		// Landmarks drive alignment and quality scoring, so models without them are not supported.
+		if len(s.Outputs) != 3*len(s.Strides) {
+			return fmt.Errorf("want scores, bboxes and landmarks per stride: %d outputs, got %d",
+				3*len(s.Strides), len(s.Outputs))
+		}
+		if s.Size[0] != s.Size[1] {
+			return fmt.Errorf("detector input must be square")
+		}
+	case RoleEmbedder:
+		if len(s.Outputs) != 1 || s.EmbeddingDim <= 0 {
+			return fmt.Errorf("one output and embedding_dim are required")
+		}
+		if s.Size[0] <= 0 || s.Size[0] != s.Size[1] {
+			return fmt.Errorf("input_size must be square for landmark alignment")
+		}
+	case RoleAttributes:
+		if len(s.Outputs) != 1 || s.Size[0] <= 0 || s.Size[1] <= 0 {
+			return fmt.Errorf("one output and input_size are required")
+		}
+		if s.AgeScale <= 0 {
+			return fmt.Errorf("age_scale must be positive")
+		}
+	case RoleLiveness:
+		if len(s.Outputs) != 1 || s.Size[0] <= 0 || s.Size[1] <= 0 {
+			return fmt.Errorf("one output and input_size are required")
+		}
+		if s.Classes < 2 || s.LiveClass < 0 || s.LiveClass >= s.Classes {
+			return fmt.Errorf("classes must be at least 2 and live_class one of them")
+		}
+		if s.CropScale < 1 {
+			return fmt.Errorf("crop_scale must be at least 1")
+		}
+	case RoleClassifier:
+		if len(s.Outputs) != 1 || s.Size[0] <= 0 || s.Size[1] <= 0 {
+			return fmt.Errorf("one output and input_size are required")
+		}
+		if s.Name == "" || len(s.Labels) < 2 {
+			return fmt.Errorf("name and at least 2 labels are required")
+		}
+		if s.CropScale < 1 {
+			return fmt.Errorf("crop_scale must be at least 1")
+		}
+		for label, penalty := range s.OcclusionPenalty {
+			if !slices.Contains(s.Labels, label) {
+				return fmt.Errorf("occlusion_penalty: unknown label %q", label)
+			}
+			if penalty < 0 || penalty >= 1 {
+				return fmt.Errorf("occlusion_penalty of %q must be in [0, 1)", label)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown role %q", s.Role)
+	}
+	return nil
+}
@@ -8,7 +8,12 @@ import (
 	"image/color"
 	"image/jpeg"
 	"log/slog"
-	"path/filepath"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,21 +23,28 @@ import (
 	"github.com/your-org/fd/internal/models"
 	"github.com/your-org/fd/internal/observability"
 	"github.com/your-org/fd/internal/queue"
+	"github.com/your-org/fd/internal/redact"
 	"github.com/your-org/fd/internal/storage"
 )
 
 // Pipeline orchestrates the full vision processing:
-// detect → track → embed → attributes → match → emit event.
+// detect → track → embed → attributes → liveness → match → emit event.
 type Pipeline struct {
-	detector   *Detector
-	embedder   *Embedder
-	attributes *AttributePredictor
-	trackers   map[uuid.UUID]*Tracker // per-stream trackers
-	db         *storage.PostgresStore
-	minio      *storage.MinIOStore
-	producer   *queue.Producer
-	cfg        config.VisionConfig
-	trackCfg   config.TrackingConfig
+	detector    *Detector
+	embedder    *Embedder
+	attributes  *AttributePredictor
+	liveness    *LivenessDetector      // nil when no liveness model is installed
+	classifiers []*Classifier          // face attribute classifiers of the manifest
+	trackers    map[uuid.UUID]*Tracker // per-stream trackers
+	counters    map[uuid.UUID]*Counter // per-stream tripwire/zone counters
+	trackersMu  sync.Mutex             // guards trackers and counters between ProcessFrame and SweepIdle
+	db          *storage.PostgresStore
+	minio       *storage.MinIOStore
+	producer    *queue.Producer
+	cfg         config.VisionConfig
+	trackCfg    config.TrackingConfig
+	batcher     *Batcher   // optional cross-pipeline batching of embed/attrs
+	embedMu     sync.Mutex // serialises EmbedImage callers (API handlers, re-embedding job)
 }
 
 // NewPipeline initialises all ONNX models and returns a ready pipeline.
@@ -44,66 +56,57 @@ func NewPipeline(
 	producer *queue.Producer,
 ) (*Pipeline, error) {
 
-	detPath := filepath.Join(cfg.ModelsDir, "det_10g.onnx")
-	embPath := filepath.Join(cfg.ModelsDir, "w600k_r50.onnx")
-	attrPath := filepath.Join(cfg.ModelsDir, "genderage.onnx")
-
-	// Build session options to cap ORT thread usage per model session.
-	// Each call to newSessionOptions() returns a fresh *ort.SessionOptions
-	// that must be destroyed after the session is created.
-	newSessionOptions := func() (*ort.SessionOptions, error) {
-		opts, err := ort.NewSessionOptions()
-		if err != nil {
-			return nil, fmt.Errorf("create session options: %w", err)
-		}
-		if cfg.IntraOpThreads > 0 {
-			if err := opts.SetIntraOpNumThreads(cfg.IntraOpThreads); err != nil {
-				opts.Destroy()
-				return nil, fmt.Errorf("set intra_op_threads: %w", err)
-			}
-		}
-		if cfg.InterOpThreads > 0 {
-			if err := opts.SetInterOpNumThreads(cfg.InterOpThreads); err != nil {
-				opts.Destroy()
-				return nil, fmt.Errorf("set inter_op_threads: %w", err)
-			}
-		}
-		return opts, nil
+	manifest, err := LoadManifest(cfg.ModelsDir)
+	if err != nil {
+		return nil, err
+	}
+	detSpec, err := manifest.Model(RoleDetector)
+	if err != nil {
+		return nil, err
+	}
+	embSpec, err := manifest.Model(RoleEmbedder)
+	if err != nil {
+		return nil, err
+	}
+	attrSpec, err := manifest.Model(RoleAttributes)
+	if err != nil {
+		return nil, err
 	}
 
-	slog.Info("loading detection model", "path", detPath,
+	slog.Info("loading detection model", "path", detSpec.File,
+		"input_size", cfg.DetectorInputSize, "resize", cfg.DetectorResize,
 		"intra_op_threads", cfg.IntraOpThreads, "inter_op_threads", cfg.InterOpThreads)
-	detOpts, err := newSessionOptions()
+	detOpts, err := newSessionOptions(cfg)
 	if err != nil {
 		return nil, err
 	}
-	det, err := NewDetector(detPath, float32(cfg.DetectionThreshold), detOpts)
+	det, err := NewDetector(detSpec, cfg.DetectorInputSize, cfg.DetectorResize, detOpts)
 	detOpts.Destroy()
 	if err != nil {
 		return nil, fmt.Errorf("load detector: %w", err)
 	}
 
-	slog.Info("loading embedding model", "path", embPath)
-	embOpts, err := newSessionOptions()
+	slog.Info("loading embedding model", "path", embSpec.File, "dim", embSpec.EmbeddingDim)
+	embOpts, err := newSessionOptions(cfg)
 	if err != nil {
 		det.Close()
 		return nil, err
 	}
-	emb, err := NewEmbedder(embPath, embOpts)
+	emb, err := NewEmbedder(embSpec, cfg.BatchSize, embOpts)
 	embOpts.Destroy()
 	if err != nil {
 		det.Close()
 		return nil, fmt.Errorf("load embedder: %w", err)
 	}
 
-	slog.Info("loading attribute model", "path", attrPath)
-	attrOpts, err := newSessionOptions()
+	slog.Info("loading attribute model", "path", attrSpec.File)
+	attrOpts, err := newSessionOptions(cfg)
 	if err != nil {
 		det.Close()
 		emb.Close()
 		return nil, err
 	}
-	attr, err := NewAttributePredictor(attrPath, attrOpts)
+	attr, err := NewAttributePredictor(attrSpec, cfg.BatchSize, attrOpts)
 	attrOpts.Destroy()
 	if err != nil {
 		det.Close()
@@ -111,23 +114,120 @@ func NewPipeline(
 		return nil, fmt.Errorf("load attributes: %w", err)
 	}
 
+	live, err := loadLiveness(manifest, cfg)
+	if err != nil {
+		det.Close()
+		emb.Close()
+		attr.Close()
+		return nil, err
+	}
+
+	var classifiers []*Classifier
+	for _, spec := range manifest.Classifiers() {
+		slog.Info("loading attribute classifier", "name", spec.Name, "path", spec.File)
+		c, err := newClassifier(spec, cfg)
+		if err != nil {
+			det.Close()
+			emb.Close()
+			attr.Close()
+			if live != nil {
+				live.Close()
+			}
+			for _, c := range classifiers {
+				c.Close()
+			}
+			return nil, err
+		}
+		classifiers = append(classifiers, c)
+	}
+
 	slog.Info("vision pipeline ready")
 
 	return &Pipeline{
-		detector:   det,
-		embedder:   emb,
-		attributes: attr,
-		trackers:   make(map[uuid.UUID]*Tracker),
-		db:         db,
-		minio:      minio,
-		producer:   producer,
-		cfg:        cfg,
-		trackCfg:   trackCfg,
+		detector:    det,
+		embedder:    emb,
+		attributes:  attr,
+		liveness:    live,
+		classifiers: classifiers,
+		trackers:    make(map[uuid.UUID]*Tracker),
+		counters:    make(map[uuid.UUID]*Counter),
+		db:          db,
+		minio:       minio,
+		producer:    producer,
+		cfg:         cfg,
+		trackCfg:    trackCfg,
 	}, nil
 }
 
-// ProcessFrame handles one frame task: detect → track → embed → attrs → match → event.
-func (p *Pipeline) ProcessFrame(ctx context.Context, task models.FrameTask) error {
+// loadLiveness loads the optional liveness model. Without one in the manifest
+// or on disk it returns nil, and streams that enable liveness get no score
+// (and, in enforce mode, no matches).
+func loadLiveness(manifest Manifest, cfg config.VisionConfig) (*LivenessDetector, error) {
+	spec, err := manifest.Model(RoleLiveness)
+	if err == nil {
+		_, err = os.Stat(spec.File)
+	}
+	if err != nil {
+		if cfg.Liveness != string(models.LivenessOff) {
+			slog.Warn("no liveness model, liveness scores unavailable (enforce mode matches no faces)", "error", err)
+		}
+		return nil, nil
+	}
+
+	slog.Info("loading liveness model", "path", spec.File)
+	opts, err := newSessionOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	live, err := NewLivenessDetector(spec, cfg.BatchSize, opts)
+	opts.Destroy()
+	if err != nil {
+		return nil, fmt.Errorf("load liveness: %w", err)
+	}
+	return live, nil
+}
+
+func newClassifier(spec ModelSpec, cfg config.VisionConfig) (*Classifier, error) {
+	opts, err := newSessionOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClassifier(spec, cfg.BatchSize, opts)
+	opts.Destroy()
+	if err != nil {
+		return nil, fmt.Errorf("load %s classifier: %w", spec.Name, err)
+	}
+	return c, nil
+}
+
+// newSessionOptions builds session options that cap ORT thread usage per model
+// session. Each call returns a fresh *ort.SessionOptions that must be destroyed
+// after the session is created.
+func newSessionOptions(cfg config.VisionConfig) (*ort.SessionOptions, error) {
+	opts, err := ort.NewSessionOptions()
+	if err != nil {
+		return nil, fmt.Errorf("create session options: %w", err)
+	}
+	if cfg.IntraOpThreads > 0 {
+		if err := opts.SetIntraOpNumThreads(cfg.IntraOpThreads); err != nil {
+			opts.Destroy()
+			return nil, fmt.Errorf("set intra_op_threads: %w", err)
+		}
+	}
+	if cfg.InterOpThreads > 0 {
+		if err := opts.SetInterOpNumThreads(cfg.InterOpThreads); err != nil {
+			opts.Destroy()
+			return nil, fmt.Errorf("set inter_op_threads: %w", err)
+		}
+	}
+	return opts, nil
+}
+
+// ProcessFrame handles one frame task: detect → track → embed → attrs → liveness → match → event.
+func (p *Pipeline) ProcessFrame(ctx context.Context, task models.FrameTask) (err error) {
+	// Global vision/tracking settings with the stream's overrides applied
+	cfg, trackCfg := applyStreamSettings(p.cfg, p.trackCfg, task.Settings)
+
 	// 1. Load frame from MinIO
 	frameData, err := p.minio.GetObject(ctx, task.FrameRef)
 	if err != nil {
@@ -140,29 +240,78 @@ func (p *Pipeline) ProcessFrame(ctx context.Context, task models.FrameTask) erro
 		return fmt.Errorf("decode jpeg: %w", err)
 	}
 
+	// identify mode: strangers get no snapshot, event, embedding or track record,
+	// and frames no reported face refers to are deleted right away. Not after an
+	// error, though: the task is redelivered and needs its frame.
+	identify := task.Mode == models.StreamModeIdentify
+	frameReferenced := false
+	if identify {
+		defer func() {
+			if frameReferenced || err != nil {
+				return
+			}
+			if err := p.minio.DeleteObject(ctx, task.FrameRef); err != nil {
+				slog.Warn("delete unreferenced frame", "error", err, "frame", task.FrameRef)
+			}
+		}()
+	}
+
 	bounds := img.Bounds()
 	origW := bounds.Dx()
 	origH := bounds.Dy()
 
 	// 2. Preprocess for detection
 	start := time.Now()
-	detInput := preprocessForDetection(img, p.detector.inputW, p.detector.inputH)
+	detTransform := p.detector.Transform(origW, origH)
+	detInput := p.detector.preprocess(img, detTransform)
 	observability.InferenceDuration.WithLabelValues("preprocess").Observe(time.Since(start).Seconds())
 
 	// 3. Detect faces
 	start = time.Now()
-	detections, err := p.detector.Detect(detInput, origW, origH)
+	detections, err := p.detector.Detect(detInput, detTransform, float32(cfg.DetectionThreshold))
 	if err != nil {
 		return fmt.Errorf("detect: %w", err)
 	}
 	observability.InferenceDuration.WithLabelValues("detect").Observe(time.Since(start).Seconds())
 
-	if len(detections) == 0 {
-		return nil // No faces
+	faces := make([][4]float32, len(detections))
+	for i, d := range detections {
+		faces[i] = d.BBox
+	}
+	var updates []TrackUpdate
+
+	// Redacted streams never refer to the ingestor's raw frame: events and live
+	// previews get a copy with every face obscured, also those filtered out
+	// below, stored once this frame's recognition passes have settled the
+	// tracks' identities. Until then (or if storing it fails) the copy does not
+	// exist, so no unredacted frame can be served.
+	redaction := models.ParseRedactionMode(cfg.Redaction)
+	redactionStyle := models.RedactionStyle(cfg.RedactionStyle)
+	frameKey := task.FrameRef
+	if redaction != models.RedactionOff {
+		frameKey = redactedFrameKey(task.FrameRef)
+	}
+	frameStored := true
+
+	// Live previews learn of the frame once it is final, i.e. after redaction
+	defer func() {
+		if (identify && !frameReferenced) || !frameStored {
+			return // deleted
+		}
+		p.publishLive(task, frameKey, faces, updates)
+	}()
+
+	if redaction != models.RedactionOff {
+		defer func() {
+			if identify && !frameReferenced {
+				return // deleted instead
+			}
+			frameStored = p.redactFrame(ctx, task.FrameRef, frameKey, frameData, img, faces, redaction, redactionStyle, updates)
+		}()
 	}
 
 	// Filter out faces smaller than min_face_size
-	minSize := float32(p.cfg.MinFaceSize)
+	minSize := float32(cfg.MinFaceSize)
 	if minSize > 0 {
 		filtered := detections[:0]
 		for _, d := range detections {
@@ -173,23 +322,64 @@ func (p *Pipeline) ProcessFrame(ctx context.Context, task models.FrameTask) erro
 			}
 		}
 		detections = filtered
-		if len(detections) == 0 {
-			return nil
+	}
+
+	// Drop faces outside the stream's ROIs or inside its exclusion polygons
+	// (posters, screens, mirrors) before they can start a track
+	if s := task.Settings; s != nil && (len(s.ROI) > 0 || len(s.Exclude) > 0) {
+		filtered := detections[:0]
+		for _, d := range detections {
+			cx := float64(d.BBox[0]+d.BBox[2]) / 2 / float64(origW)
+			cy := float64(d.BBox[1]+d.BBox[3]) / 2 / float64(origH)
+			if s.AllowsPoint(cx, cy) {
+				filtered = append(filtered, d)
+			}
 		}
+		detections = filtered
+	}
+
+	if len(detections) > 0 {
+		observability.FacesDetected.WithLabelValues(task.StreamID.String()).Add(float64(len(detections)))
 	}
 
-	observability.FacesDetected.WithLabelValues(task.StreamID.String()).Add(float64(len(detections)))
+	// 4. Update tracker (also on empty frames, so tracks age and expire)
+	tracker := p.getTracker(task.StreamID, trackCfg)
+	updates = tracker.Update(detections, task.Timestamp)
 
-	// 4. Update tracker
-	tracker := p.getTracker(task.StreamID)
-	updates := tracker.Update(detections)
+	// Tripwire crossings and zone occupancy follow every track, recognized or not
+	counter := p.getCounter(task.StreamID)
+	p.publishAnalytics(ctx, task.StreamID, task.Timestamp,
+		counter.Update(updates, origW, origH, task.Settings, trackCfg.MaxAge))
 
-	// 5. For each tracked face that needs processing
+	// 5. Select the tracked faces that need a recognition pass and prepare their model inputs
+	checkLiveness := cfg.Liveness != string(models.LivenessOff) && p.liveness != nil
+	var pending []pendingFace
 	for _, upd := range updates {
 		track := upd.Track
 
-		needRecognition := tracker.ShouldRecognize(track, p.trackCfg.ReRecognizeInterval)
-		if !needRecognition && !upd.IsNew {
+		if upd.Ended {
+			if track.started {
+				p.publishTrackEvent(ctx, task.StreamID, models.TrackEnded, track)
+			}
+			continue
+		}
+
+		// Score every crop so a clearly better shot triggers a recognition pass of its own.
+		// Crops below the minimum quality are never sent to recognition.
+		quality := AssessQuality(img, track.BBox, track.Landmarks, track.Confidence)
+		if quality.Score < cfg.MinFaceQuality {
+			continue
+		}
+		// Extreme profiles and tilts produce confidently wrong matches, so they
+		// wait for a more frontal shot too
+		if pose := quality.Pose; (cfg.MaxYaw >= 0 && abs32(pose.Yaw) > cfg.MaxYaw) ||
+			(cfg.MaxPitch >= 0 && abs32(pose.Pitch) > cfg.MaxPitch) {
+			continue
+		}
+		betterShot := quality.Score > track.BestQuality+trackCfg.BestShotMargin
+
+		needRecognition := tracker.ShouldRecognize(track, trackCfg.ReRecognizeInterval)
+		if !needRecognition && !upd.IsNew && !betterShot {
 			continue
 		}
 
@@ -198,99 +388,375 @@ func (p *Pipeline) ProcessFrame(ctx context.Context, task models.FrameTask) erro
 		if faceCrop == nil {
 			continue
 		}
+		embInput, err := p.embeddingInput(img, track.BBox, track.Landmarks)
+		if err != nil {
+			slog.Warn("embed error", "error", err, "track", track.ID)
+			continue
+		}
+		var liveInput []float32
+		if checkLiveness {
+			if liveInput = p.liveness.preprocess(img, track.BBox); liveInput == nil {
+				continue
+			}
+		}
+		clsInputs := make([][]float32, len(p.classifiers))
+		for ci, c := range p.classifiers {
+			clsInputs[ci] = c.preprocess(img, track.BBox)
+		}
+		if slices.ContainsFunc(clsInputs, func(in []float32) bool { return in == nil }) {
+			continue
+		}
+
+		pending = append(pending, pendingFace{
+			upd:       upd,
+			quality:   quality,
+			faceCrop:  faceCrop,
+			embInput:  embInput,
+			attrInput: p.attributes.preprocess(faceCrop),
+			liveInput: liveInput,
+			clsInputs: clsInputs,
+		})
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// 6. Embeddings (landmark-aligned) and gender/age of all selected faces, one batched run each
+	embInputs := make([][]float32, len(pending))
+	attrInputs := make([][]float32, len(pending))
+	for i, pf := range pending {
+		embInputs[i] = pf.embInput
+		attrInputs[i] = pf.attrInput
+	}
+	inferred := p.inferFaces(ctx, embInputs, attrInputs)
+	if inferred.embedErr != nil {
+		slog.Warn("embed error", "error", inferred.embedErr, "faces", len(pending))
+		return nil
+	}
+	if inferred.attrErr != nil {
+		slog.Warn("attributes error", "error", inferred.attrErr, "faces", len(pending))
+	}
+
+	// Liveness of the selected crops, only for streams that check it
+	var liveScores []float32
+	if checkLiveness {
+		liveInputs := make([][]float32, len(pending))
+		for i, pf := range pending {
+			liveInputs[i] = pf.liveInput
+		}
+		start = time.Now()
+		liveScores, err = p.liveness.ScoreBatch(liveInputs)
+		observability.InferenceDuration.WithLabelValues("liveness").Observe(time.Since(start).Seconds())
+		if err != nil {
+			slog.Warn("liveness error", "error", err, "faces", len(pending))
+			liveScores = nil
+		}
+	}
 
-		// 6. Extract embedding
+	// Face attribute classifiers (mask, eyewear, hat, ...), label probabilities per classifier and face
+	clsProbs := make([][][]float32, len(p.classifiers))
+	for ci, c := range p.classifiers {
+		inputs := make([][]float32, len(pending))
+		for i, pf := range pending {
+			inputs[i] = pf.clsInputs[ci]
+		}
 		start = time.Now()
-		embInput := preprocessForEmbedding(faceCrop, p.embedder.inputW, p.embedder.inputH)
-		embedding, err := p.embedder.Extract(embInput)
+		probs, err := c.PredictBatch(inputs)
+		observability.InferenceDuration.WithLabelValues("classify").Observe(time.Since(start).Seconds())
 		if err != nil {
-			slog.Warn("embed error", "error", err, "track", track.ID)
+			slog.Warn("classifier error", "error", err, "classifier", c.Name(), "faces", len(pending))
 			continue
 		}
-		observability.InferenceDuration.WithLabelValues("embed").Observe(time.Since(start).Seconds())
+		clsProbs[ci] = probs
+	}
+
+	for i, pf := range pending {
+		track := pf.upd.Track
+		quality := pf.quality
+		faceCrop := pf.faceCrop
+		embedding := inferred.embeddings[i]
+
+		// Re-attach to a recently lost track of the same face (occlusion, brief exit)
+		isNew := pf.upd.IsNew
+		if merged, ok := tracker.Reidentify(track, embedding); ok {
+			slog.Debug("track re-identified", "track", merged.ID, "replaced", track.ID)
+			p.publishAnalytics(ctx, task.StreamID, task.Timestamp, counter.Merge(track.ID, merged.ID))
+			track = merged
+			isNew = false
+		}
 
 		track.Embedding = embedding
 		track.LastRecognized = time.Now()
 
-		// 7. Predict gender/age
-		start = time.Now()
-		attrInput := preprocessForAttributes(faceCrop, p.attributes.inputW, p.attributes.inputH)
-		ga, err := p.attributes.Predict(attrInput)
-		if err != nil {
-			slog.Warn("attributes error", "error", err, "track", track.ID)
-		} else {
-			track.Gender = ga.Gender
-			track.GenderConf = ga.GenderConfidence
-			track.FaceAge = ga.Age
-			track.AgeRange = ga.AgeRange
+		// 7. Fold gender/age into the track's quality-weighted aggregate
+		if inferred.attrErr == nil {
+			smoothed := track.attrs.add(inferred.attributes[i], quality.Score)
+			track.Gender = smoothed.Gender
+			track.GenderConf = smoothed.GenderConfidence
+			track.FaceAge = smoothed.Age
+			track.AgeRange = smoothed.AgeRange
+			track.AttrSamples = track.attrs.samples
+		}
+		for ci, c := range p.classifiers {
+			if clsProbs[ci] != nil {
+				c.fold(track, clsProbs[ci][i], quality.Score)
+			}
 		}
-		observability.InferenceDuration.WithLabelValues("attrs").Observe(time.Since(start).Seconds())
 
-		// 8. Match against DB
-		var matchedPersonID *uuid.UUID
-		var matchScore float32
+		// A spoof (photo, screen) is still reported, but with liveness enforced
+		// it neither matches nor votes on the track's identity. Neither does a
+		// face that could not be scored (no model, inference error).
+		var livenessScore *float32
+		spoof := false
+		if liveScores != nil {
+			livenessScore = &liveScores[i]
+			spoof = liveScores[i] < cfg.LivenessThreshold
+			if spoof {
+				observability.SpoofsDetected.WithLabelValues(task.StreamID.String()).Inc()
+			}
+		}
+		suppress := cfg.Liveness == string(models.LivenessEnforce) && (spoof || livenessScore == nil)
+
+		// 8. Match against DB, then vote the instantaneous match into the track's identity
+		var instantPersonID *uuid.UUID
+		var instantScore float32
 
 		start = time.Now()
-		matches, err := p.db.SearchFaces(ctx, embedding, task.CollectionID, p.cfg.RecognitionThreshold, 1)
-		if err != nil {
-			slog.Warn("search error", "error", err)
-		} else if len(matches) > 0 {
-			matchedPersonID = &matches[0].PersonID
-			matchScore = matches[0].Score
-			track.PersonID = matches[0].PersonID.String()
-			track.MatchScore = matchScore
+		var matches []storage.SearchMatch
+		var searchErr error
+		if (!identify || task.CollectionID != nil) && !suppress {
+			matches, searchErr = p.db.SearchFaces(ctx, embedding, p.embedder.Version(), task.CollectionID, cfg.RecognitionThreshold, 1)
+		}
+		if searchErr != nil {
+			slog.Warn("search error", "error", searchErr)
+		} else if !suppress {
+			voteID, voteWeight := "", float32(cfg.RecognitionThreshold)
+			if len(matches) > 0 {
+				instantPersonID = &matches[0].PersonID
+				instantScore = matches[0].Score
+				voteID, voteWeight = instantPersonID.String(), instantScore
+			}
+			if tracker.VoteIdentity(track, voteID, voteWeight) {
+				slog.Debug("track identity changed", "track", track.ID, "person", track.PersonID)
+			}
+		}
+		observability.InferenceDuration.WithLabelValues("match").Observe(time.Since(start).Seconds())
 
+		var matchedPersonID *uuid.UUID
+		var matchScore float32
+		if id, err := uuid.Parse(track.PersonID); err == nil && !suppress {
+			matchedPersonID = &id
+			matchScore = track.MatchScore
 			observability.FacesRecognized.WithLabelValues(task.StreamID.String()).Inc()
 		}
-		observability.InferenceDuration.WithLabelValues("match").Observe(time.Since(start).Seconds())
+		if identify && matchedPersonID == nil {
+			// Still remember the best quality seen, so only a clearly better shot re-triggers recognition
+			track.BestQuality = max(track.BestQuality, quality.Score)
+			continue
+		}
 
-		// 9. Save face snapshot to MinIO only on first sighting (avoid redundant writes)
-		var snapshotKey string
-		if upd.IsNew {
-			snapshotKey = fmt.Sprintf("snapshots/%s/%s_%s.jpg",
-				task.StreamID.String(), track.ID, time.Now().Format("20060102_150405"))
+		// 9. Save the face snapshot when this is the track's best shot so far.
+		// isNew still forces a first snapshot for low-quality tracks, and a track
+		// identified since its best shot was obscured gets a visible one.
+		bestShot := false
+		obscure := redaction == models.RedactionAll || (redaction == models.RedactionUnknown && matchedPersonID == nil)
+		if isNew || track.BestSnapshotKey == "" || quality.Score > track.BestQuality+trackCfg.BestShotMargin ||
+			(track.BestRedacted && !obscure) {
+			snapshotKey := fmt.Sprintf("snapshots/%s/%s_%s.jpg",
+				task.StreamID.String(), track.ID, time.Now().Format("20060102_150405.000"))
 			snapshotImg := upscaleFace(faceCrop, 100)
+			if obscure {
+				snapshotImg = redact.Whole(snapshotImg, redactionStyle)
+				snapshotKey = strings.TrimSuffix(snapshotKey, ".jpg") + models.RedactedImageSuffix
+			}
 			snapshotData := encodeJPEG(snapshotImg, 100)
 			if err := p.minio.PutObject(ctx, snapshotKey, snapshotData, "image/jpeg"); err != nil {
 				slog.Warn("save snapshot", "error", err)
-				snapshotKey = ""
+			} else {
+				track.BestSnapshotKey = snapshotKey
+				track.BestRedacted = obscure
+				track.BestQuality = max(track.BestQuality, quality.Score)
+				bestShot = true
 			}
 		}
 
-		// 10. Publish detection event
+		// 10. Publish detection event. Occluded faces (mask, sunglasses) report lower
+		// match scores than the raw similarity.
+		occlusion := occlusionFactor(p.classifiers, track)
 		result := models.DetectionResult{
 			StreamID:         task.StreamID,
 			TrackID:          track.ID,
 			Timestamp:        task.Timestamp,
 			BBox:             track.BBox,
+			Landmarks:        track.Landmarks,
 			Gender:           track.Gender,
 			GenderConfidence: track.GenderConf,
 			Age:              track.FaceAge,
 			AgeRange:         track.AgeRange,
+			AttributeSamples: track.AttrSamples,
 			Confidence:       track.Confidence,
 			Embedding:        embedding,
+			ModelVersion:     p.embedder.Version(),
 			MatchedPersonID:  matchedPersonID,
-			MatchScore:       matchScore,
-			SnapshotKey:      snapshotKey,
-			FrameKey:         task.FrameRef,
+			MatchScore:       matchScore * occlusion,
+			InstantPersonID:  instantPersonID,
+			InstantScore:     instantScore * occlusion,
+			SnapshotKey:      track.BestSnapshotKey,
+			FrameKey:         frameKey,
+			QualityScore:     quality.Score,
+			BestShot:         bestShot,
+			LivenessScore:    livenessScore,
+			Spoof:            spoof,
+			Attributes:       maps.Clone(track.FaceAttrs),
+			Pose:             quality.Pose,
 		}
 
 		if err := p.producer.PublishEvent(ctx, task.StreamID.String(), result); err != nil {
 			slog.Error("publish event", "error", err, "track", track.ID)
 		}
+		frameReferenced = true
+
+		// 11. Track lifecycle: first recognition pass starts the track, later passes update it
+		if !track.started {
+			track.started = true
+			p.publishTrackEvent(ctx, task.StreamID, models.TrackStarted, track)
+		} else {
+			p.publishTrackEvent(ctx, task.StreamID, models.TrackUpdated, track)
+		}
 	}
 
 	return nil
 }
 
+// SweepIdle ends all tracks of streams that have sent no frames for idle
+// (e.g. the stream was stopped) and publishes their track_ended events.
+func (p *Pipeline) SweepIdle(ctx context.Context, idle time.Duration) {
+	p.trackersMu.Lock()
+	var stale []uuid.UUID
+	var flushed [][]*Track
+	var counts [][]models.AnalyticsCount
+	for streamID, t := range p.trackers {
+		if !t.Idle(idle) {
+			continue
+		}
+		stale = append(stale, streamID)
+		flushed = append(flushed, t.Flush())
+		delete(p.trackers, streamID)
+
+		var c []models.AnalyticsCount
+		if counter, ok := p.counters[streamID]; ok {
+			c = counter.Flush()
+			delete(p.counters, streamID)
+		}
+		counts = append(counts, c)
+	}
+	p.trackersMu.Unlock()
+
+	for i, streamID := range stale {
+		for _, track := range flushed[i] {
+			if track.started {
+				p.publishTrackEvent(ctx, streamID, models.TrackEnded, track)
+			}
+		}
+		p.publishAnalytics(ctx, streamID, time.Now(), counts[i])
+	}
+}
+
+// publishTrackEvent emits a track lifecycle event with the track's current summary.
+func (p *Pipeline) publishTrackEvent(ctx context.Context, streamID uuid.UUID, typ models.TrackEventType, track *Track) {
+	ev := models.TrackEvent{
+		Type:             typ,
+		StreamID:         streamID,
+		TrackID:          track.ID,
+		FirstSeen:        track.FirstSeen,
+		LastSeen:         track.LastSeen,
+		DwellSeconds:     track.Dwell().Seconds(),
+		FrameCount:       track.FrameCount,
+		Gender:           track.Gender,
+		GenderConfidence: track.GenderConf,
+		Age:              track.FaceAge,
+		AgeRange:         track.AgeRange,
+		AttributeSamples: track.AttrSamples,
+	}
+	if id, err := uuid.Parse(track.PersonID); err == nil {
+		ev.PersonID = &id
+		ev.MatchScore = track.MatchScore
+	}
+
+	if err := p.producer.PublishTrackEvent(ctx, streamID.String(), ev); err != nil {
+		slog.Error("publish track event", "error", err, "type", typ, "track", track.ID)
+	}
+}
+
+// publishLive announces a processed frame with its tracked faces to live previews.
+func (p *Pipeline) publishLive(task models.FrameTask, frameKey string, faces [][4]float32, updates []TrackUpdate) {
+	frame := models.LiveFrame{
+		StreamID:  task.StreamID,
+		Timestamp: task.Timestamp,
+		FrameRef:  frameKey,
+		Faces:     faces,
+		Tracks:    []models.LiveTrack{},
+	}
+	for _, upd := range updates {
+		if upd.Ended {
+			continue
+		}
+		t := upd.Track
+		lt := models.LiveTrack{
+			TrackID:    t.ID,
+			BBox:       t.BBox,
+			Landmarks:  t.Landmarks,
+			MatchScore: t.MatchScore,
+			Gender:     t.Gender,
+			Age:        t.FaceAge,
+		}
+		if id, err := uuid.Parse(t.PersonID); err == nil {
+			lt.PersonID = &id
+		}
+		frame.Tracks = append(frame.Tracks, lt)
+	}
+	if err := p.producer.PublishLive(task.StreamID.String(), frame); err != nil {
+		slog.Warn("publish live frame", "error", err, "stream_id", task.StreamID)
+	}
+}
+
+// publishAnalytics updates the counting metrics and emits the changed counters.
+func (p *Pipeline) publishAnalytics(ctx context.Context, streamID uuid.UUID, ts time.Time, counts []models.AnalyticsCount) {
+	if len(counts) == 0 {
+		return
+	}
+
+	sid := streamID.String()
+	for _, c := range counts {
+		switch c.Kind {
+		case models.AnalyticsLine:
+			observability.LineCrossings.WithLabelValues(sid, c.Name, "in").Add(float64(c.In))
+			observability.LineCrossings.WithLabelValues(sid, c.Name, "out").Add(float64(c.Out))
+		case models.AnalyticsZone:
+			observability.ZoneEntries.WithLabelValues(sid, c.Name).Add(float64(c.In))
+			observability.ZoneExits.WithLabelValues(sid, c.Name).Add(float64(c.Out))
+			observability.ZoneOccupancy.WithLabelValues(sid, c.Name).Set(float64(c.Occupancy))
+		}
+	}
+
+	ev := models.AnalyticsEvent{StreamID: streamID, Timestamp: ts, Counts: counts}
+	if err := p.producer.PublishAnalyticsEvent(ctx, sid, ev); err != nil {
+		slog.Error("publish analytics event", "error", err, "stream_id", sid)
+	}
+}
+
 // EmbedImage extracts an embedding from a standalone image (for AddFace endpoint).
-func (p *Pipeline) EmbedImage(imageData []byte) ([]float32, float32, error) {
+// Safe for concurrent use.
+func (p *Pipeline) EmbedImage(imageData []byte) ([]float32, models.FaceQuality, error) {
+	p.embedMu.Lock()
+	defer p.embedMu.Unlock()
+
 	img, err := jpeg.Decode(bytes.NewReader(imageData))
 	if err != nil {
 		// Try other formats
 		img, _, err = image.Decode(bytes.NewReader(imageData))
 		if err != nil {
-			return nil, 0, fmt.Errorf("decode image: %w", err)
+			return nil, models.FaceQuality{}, fmt.Errorf("decode image: %w", err)
 		}
 	}
 
@@ -299,13 +765,14 @@ func (p *Pipeline) EmbedImage(imageData []byte) ([]float32, float32, error) {
 	origH := bounds.Dy()
 
 	// Detect face
-	detInput := preprocessForDetection(img, p.detector.inputW, p.detector.inputH)
-	detections, err := p.detector.Detect(detInput, origW, origH)
+	detTransform := p.detector.Transform(origW, origH)
+	detInput := p.detector.preprocess(img, detTransform)
+	detections, err := p.detector.Detect(detInput, detTransform, float32(p.cfg.DetectionThreshold))
 	if err != nil {
-		return nil, 0, fmt.Errorf("detect: %w", err)
+		return nil, models.FaceQuality{}, fmt.Errorf("detect: %w", err)
 	}
 	if len(detections) == 0 {
-		return nil, 0, fmt.Errorf("no face detected in image")
+		return nil, models.FaceQuality{}, fmt.Errorf("no face detected in image")
 	}
 
 	// Use the highest confidence detection
@@ -316,31 +783,98 @@ func (p *Pipeline) EmbedImage(imageData []byte) ([]float32, float32, error) {
 		}
 	}
 
-	faceCrop := cropFace(img, best.BBox)
-	if faceCrop == nil {
-		return nil, 0, fmt.Errorf("failed to crop face")
+	embedding, err := p.embedFace(img, best.BBox, best.Landmarks)
+	if err != nil {
+		return nil, models.FaceQuality{}, fmt.Errorf("embed: %w", err)
 	}
 
-	embInput := preprocessForEmbedding(faceCrop, p.embedder.inputW, p.embedder.inputH)
-	embedding, err := p.embedder.Extract(embInput)
+	return embedding, AssessQuality(img, best.BBox, best.Landmarks, best.Confidence), nil
+}
+
+// ModelVersion returns the version of the embedding model, recorded with every
+// embedding the pipeline produces.
+func (p *Pipeline) ModelVersion() string {
+	return p.embedder.Version()
+}
+
+// embedFace warps the face onto the ArcFace 5-point template and extracts its embedding.
+func (p *Pipeline) embedFace(img image.Image, bbox [4]float32, landmarks [5][2]float32) ([]float32, error) {
+	embInput, err := p.embeddingInput(img, bbox, landmarks)
 	if err != nil {
-		return nil, 0, fmt.Errorf("embed: %w", err)
+		return nil, err
+	}
+	return p.embedder.Extract(embInput)
+}
+
+// embeddingInput returns the embedder input for a face warped onto the ArcFace
+// 5-point template. Shared by live frames and enrollment so both produce
+// comparable vectors. Falls back to a padded bbox crop when the landmarks are degenerate.
+func (p *Pipeline) embeddingInput(img image.Image, bbox [4]float32, landmarks [5][2]float32) ([]float32, error) {
+	face := alignFace(img, landmarks, p.embedder.inputW)
+	if face == nil {
+		face = cropFace(img, bbox)
+		if face == nil {
+			return nil, fmt.Errorf("failed to crop face")
+		}
 	}
+	return p.embedder.preprocess(face), nil
+}
+
+// pendingFace is a tracked face selected for a recognition pass in ProcessFrame.
+type pendingFace struct {
+	upd       TrackUpdate
+	quality   models.FaceQuality
+	faceCrop  image.Image
+	embInput  []float32
+	attrInput []float32
+	liveInput []float32   // nil unless the stream checks liveness
+	clsInputs [][]float32 // per attribute classifier
+}
+
+// UseBatcher routes the pipeline's embedding and attribute inference through a
+// shared Batcher instead of its own sessions.
+func (p *Pipeline) UseBatcher(b *Batcher) {
+	p.batcher = b
+}
 
-	return embedding, best.Confidence, nil
+// inferFaces runs the embedding and attribute models over one frame's crops,
+// through the shared batcher if one is set.
+func (p *Pipeline) inferFaces(ctx context.Context, embInputs, attrInputs [][]float32) faceBatchResult {
+	if p.batcher != nil {
+		return p.batcher.Infer(ctx, embInputs, attrInputs)
+	}
+	return runFaceModels(p.embedder, p.attributes, embInputs, attrInputs)
 }
 
-func (p *Pipeline) getTracker(streamID uuid.UUID) *Tracker {
+// getTracker returns the stream's tracker, configured with cfg.
+func (p *Pipeline) getTracker(streamID uuid.UUID, cfg config.TrackingConfig) *Tracker {
+	p.trackersMu.Lock()
+	defer p.trackersMu.Unlock()
 	if t, ok := p.trackers[streamID]; ok {
+		t.Configure(cfg)
 		return t
 	}
-	t := NewTracker(streamID.String(), p.trackCfg.MaxAge, p.trackCfg.MinHits)
+	t := NewTracker(streamID.String(), cfg)
 	p.trackers[streamID] = t
 	return t
 }
 
+// getCounter returns the stream's tripwire/zone counter.
+func (p *Pipeline) getCounter(streamID uuid.UUID) *Counter {
+	p.trackersMu.Lock()
+	defer p.trackersMu.Unlock()
+	if c, ok := p.counters[streamID]; ok {
+		return c
+	}
+	c := NewCounter()
+	p.counters[streamID] = c
+	return c
+}
+
 // Close releases all ONNX sessions.
 func (p *Pipeline) Close() {
+	p.embedMu.Lock()
+	defer p.embedMu.Unlock()
 	if p.detector != nil {
 		p.detector.Close()
 	}
@@ -350,28 +884,71 @@ func (p *Pipeline) Close() {
 	if p.attributes != nil {
 		p.attributes.Close()
 	}
+	if p.liveness != nil {
+		p.liveness.Close()
+	}
+	for _, c := range p.classifiers {
+		c.Close()
+	}
 }
 
 // --- Image preprocessing helpers ---
 
-func preprocessForDetection(img image.Image, targetW, targetH int) []float32 {
-	return imageToFloat32CHW(img, targetW, targetH, [3]float32{127.5, 127.5, 127.5}, [3]float32{128.0, 128.0, 128.0})
+// preprocess places img into the detector input as described by tr.
+// In letterbox mode the padding around the resized frame is black, as in InsightFace.
+func (d *Detector) preprocess(img image.Image, tr InputTransform) []float32 {
+	targetW, targetH := d.inputW, d.inputH
+	mean, std := d.mean, d.std
+
+	data := make([]float32, 3*targetH*targetW)
+	planeSize := targetH * targetW
+	if tr.PadX > 0 || tr.PadY > 0 {
+		for c := 0; c < 3; c++ {
+			fill := -mean[c] / std[c]
+			plane := data[c*planeSize : (c+1)*planeSize]
+			for i := range plane {
+				plane[i] = fill
+			}
+		}
+	}
+
+	x0 := int(tr.PadX)
+	y0 := int(tr.PadY)
+	w := int(math.Round(float64(float32(tr.OrigW) / tr.ScaleX)))
+	h := int(math.Round(float64(float32(tr.OrigH) / tr.ScaleY)))
+	dst := image.Rect(x0, y0, x0+w, y0+h).Intersect(image.Rect(0, 0, targetW, targetH))
+
+	drawFloat32CHW(data, targetW, targetH, dst, img, mean, std)
+	return data
 }
 
-func preprocessForEmbedding(img image.Image, targetW, targetH int) []float32 {
-	return imageToFloat32CHW(img, targetW, targetH, [3]float32{127.5, 127.5, 127.5}, [3]float32{127.5, 127.5, 127.5})
+func (e *Embedder) preprocess(img image.Image) []float32 {
+	return imageToFloat32CHW(img, e.inputW, e.inputH, e.mean, e.std)
 }
 
-func preprocessForAttributes(img image.Image, targetW, targetH int) []float32 {
-	return imageToFloat32CHW(img, targetW, targetH, [3]float32{0, 0, 0}, [3]float32{1, 1, 1})
+func (p *AttributePredictor) preprocess(img image.Image) []float32 {
+	return imageToFloat32CHW(img, p.inputW, p.inputH, p.mean, p.std)
 }
 
 // imageToFloat32CHW resizes img to targetW×targetH and converts to CHW float32
 // in a single pass, normalising as: pixel = (pixel - mean) / std.
-// Direct pixel access avoids the image.Image interface overhead.
 func imageToFloat32CHW(img image.Image, targetW, targetH int, mean, std [3]float32) []float32 {
 	data := make([]float32, 3*targetH*targetW)
+	drawFloat32CHW(data, targetW, targetH, image.Rect(0, 0, targetW, targetH), img, mean, std)
+	return data
+}
+
+// drawFloat32CHW resizes img into the dst rectangle of a CHW float32 buffer of
+// targetW×targetH, normalising as: pixel = (pixel - mean) / std.
+// Pixels outside dst are left untouched.
+// Direct pixel access avoids the image.Image interface overhead.
+func drawFloat32CHW(data []float32, targetW, targetH int, dst image.Rectangle, img image.Image, mean, std [3]float32) {
 	planeSize := targetH * targetW
+	dstW := dst.Dx()
+	dstH := dst.Dy()
+	if dstW <= 0 || dstH <= 0 {
+		return
+	}
 
 	bounds := img.Bounds()
 	srcW := bounds.Dx()
@@ -382,10 +959,10 @@ func imageToFloat32CHW(img image.Image, targetW, targetH int, mean, std [3]float
 	// Fast path: source is already *image.RGBA (most common after cropFace / SubImage)
 	switch src := img.(type) {
 	case *image.RGBA:
-		for y := 0; y < targetH; y++ {
-			srcY := minY + y*srcH/targetH
-			for x := 0; x < targetW; x++ {
-				srcX := minX + x*srcW/targetW
+		for y := dst.Min.Y; y < dst.Max.Y; y++ {
+			srcY := minY + (y-dst.Min.Y)*srcH/dstH
+			for x := dst.Min.X; x < dst.Max.X; x++ {
+				srcX := minX + (x-dst.Min.X)*srcW/dstW
 				off := src.PixOffset(srcX, srcY)
 				pix := src.Pix[off : off+3 : off+3]
 				idx := y*targetW + x
@@ -395,10 +972,10 @@ func imageToFloat32CHW(img image.Image, targetW, targetH int, mean, std [3]float
 			}
 		}
 	case *image.YCbCr:
-		for y := 0; y < targetH; y++ {
-			srcY := minY + y*srcH/targetH
-			for x := 0; x < targetW; x++ {
-				srcX := minX + x*srcW/targetW
+		for y := dst.Min.Y; y < dst.Max.Y; y++ {
+			srcY := minY + (y-dst.Min.Y)*srcH/dstH
+			for x := dst.Min.X; x < dst.Max.X; x++ {
+				srcX := minX + (x-dst.Min.X)*srcW/dstW
 				yi := src.YOffset(srcX, srcY)
 				ci := src.COffset(srcX, srcY)
 				r8, g8, b8 := color.YCbCrToRGB(src.Y[yi], src.Cb[ci], src.Cr[ci])
@@ -410,10 +987,10 @@ func imageToFloat32CHW(img image.Image, targetW, targetH int, mean, std [3]float
 		}
 	default:
 		// Slow path: generic interface (handles NRGBA, Gray, etc.)
-		for y := 0; y < targetH; y++ {
-			srcY := minY + y*srcH/targetH
-			for x := 0; x < targetW; x++ {
-				srcX := minX + x*srcW/targetW
+		for y := dst.Min.Y; y < dst.Max.Y; y++ {
+			srcY := minY + (y-dst.Min.Y)*srcH/dstH
+			for x := dst.Min.X; x < dst.Max.X; x++ {
+				srcX := minX + (x-dst.Min.X)*srcW/dstW
 				r, g, b, _ := img.At(srcX, srcY).RGBA()
 				idx := y*targetW + x
 				data[idx] = (float32(r>>8) - mean[0]) / std[0]
@@ -422,8 +999,6 @@ func imageToFloat32CHW(img image.Image, targetW, targetH int, mean, std [3]float
 			}
 		}
 	}
-
-	return data
 }
 
 // resizeImage performs nearest-neighbour resize. Returns *image.RGBA.
@@ -0,0 +1,130 @@
+package vision
+
+import (
+	"math"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+// Depth of the eye centres and the mouth corners behind the nose tip, in
+// arcfaceTemplate pixels (about 26 and 24 mm on an adult face with 63 mm
+// between the pupils).
+const (
+	poseEyeDepth   = 14.5
+	poseMouthDepth = 13.4
+)
+
+// poseModel is a generic 3D face in camera axes (x right, y down, z away from
+// the camera), centred on its mean point. x and y are the ArcFace template, so
+// a face matching the template exactly has zero yaw, pitch and roll.
+var poseModel, poseModelInv = newPoseModel()
+
+func newPoseModel() ([5][3]float64, [3][3]float64) {
+	depth := [5]float64{poseEyeDepth, poseEyeDepth, 0, poseMouthDepth, poseMouthDepth}
+	var model [5][3]float64
+	var mean [3]float64
+	for i, p := range arcfaceTemplate {
+		model[i] = [3]float64{float64(p[0]), float64(p[1]), depth[i]}
+		for k := range mean {
+			mean[k] += model[i][k] / 5
+		}
+	}
+	var cov [3][3]float64
+	for i := range model {
+		for k := range mean {
+			model[i][k] -= mean[k]
+		}
+		for r := 0; r < 3; r++ {
+			for c := 0; c < 3; c++ {
+				cov[r][c] += model[i][r] * model[i][c]
+			}
+		}
+	}
+	return model, invert3(cov)
+}
+
+// EstimatePose solves the head pose from the 5-point landmarks by fitting
+// poseModel under weak perspective (a PnP solve for a camera much farther away
+// than the face is deep): a least-squares affine projection of the model onto
+// the landmarks, whose rows are then made orthonormal to get the rotation.
+// Angles are in degrees; yaw is positive when the face turns towards the
+// image's right, pitch when it looks up, roll when it tilts clockwise.
+// Returns the zero pose for degenerate landmarks.
+func EstimatePose(lm [5][2]float32) models.HeadPose {
+	var mu, mv float64
+	for _, p := range lm {
+		mu += float64(p[0]) / 5
+		mv += float64(p[1]) / 5
+	}
+	var bu, bv [3]float64
+	for i, p := range lm {
+		u, v := float64(p[0])-mu, float64(p[1])-mv
+		for k := 0; k < 3; k++ {
+			bu[k] += poseModel[i][k] * u
+			bv[k] += poseModel[i][k] * v
+		}
+	}
+	r1 := mulVec3(poseModelInv, bu)
+	r2 := mulVec3(poseModelInv, bv)
+
+	n1, n2 := norm3(r1), norm3(r2)
+	if n1 < 1e-6 || n2 < 1e-6 {
+		return models.HeadPose{}
+	}
+	for k := range r1 {
+		r1[k] /= n1
+		r2[k] /= n2
+	}
+	// Split the non-orthogonality evenly between the two rows
+	d := dot3(r1, r2) / 2
+	for k := range r1 {
+		r1[k], r2[k] = r1[k]-d*r2[k], r2[k]-d*r1[k]
+	}
+	n1, n2 = norm3(r1), norm3(r2)
+	for k := range r1 {
+		r1[k] /= n1
+		r2[k] /= n2
+	}
+	r3 := [3]float64{
+		r1[1]*r2[2] - r1[2]*r2[1],
+		r1[2]*r2[0] - r1[0]*r2[2],
+		r1[0]*r2[1] - r1[1]*r2[0],
+	}
+
+	// R = Rz(roll) · Ry(-yaw) · Rx(-pitch) with rows r1, r2, r3; yaw and pitch
+	// are the negated turns about the camera's y (down) and x axes
+	const deg = 180 / math.Pi
+	return models.HeadPose{
+		Yaw:   float32(math.Asin(math.Max(-1, math.Min(1, r3[0]))) * deg),
+		Pitch: float32(math.Atan2(-r3[1], r3[2]) * deg),
+		Roll:  float32(math.Atan2(r2[0], r1[0]) * deg),
+	}
+}
+
+func invert3(m [3][3]float64) [3][3]float64 {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	var inv [3][3]float64
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			// Cofactor of (c, r), i.e. the transposed cofactor matrix
+			r1, r2 := (c+1)%3, (c+2)%3
+			c1, c2 := (r+1)%3, (r+2)%3
+			inv[r][c] = (m[r1][c1]*m[r2][c2] - m[r1][c2]*m[r2][c1]) / det
+		}
+	}
+	return inv
+}
+
+func mulVec3(m [3][3]float64, v [3]float64) [3]float64 {
+	return [3]float64{dot3(m[0], v), dot3(m[1], v), dot3(m[2], v)}
+}
+
+func dot3(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func norm3(v [3]float64) float64 {
+	return math.Sqrt(dot3(v, v))
+}
@@ -0,0 +1,118 @@
+package vision
+
+import (
+	"math"
+	"testing"
+)
+
+// projectPose renders the landmarks of poseModel turned by yaw, pitch and roll
+// (degrees, EstimatePose's conventions) under weak perspective.
+func projectPose(yaw, pitch, roll, scale, tx, ty float64) [5][2]float32 {
+	const rad = math.Pi / 180
+	rz := rotation(2, roll*rad)
+	ry := rotation(1, -yaw*rad)
+	rx := rotation(0, -pitch*rad)
+	r := mulMat3(rz, mulMat3(ry, rx))
+
+	var lm [5][2]float32
+	for i, p := range poseModel {
+		lm[i] = [2]float32{
+			float32(scale*dot3(r[0], p) + tx),
+			float32(scale*dot3(r[1], p) + ty),
+		}
+	}
+	return lm
+}
+
+// rotation returns the rotation by a radians about camera axis 0 (x), 1 (y) or 2 (z).
+func rotation(axis int, a float64) [3][3]float64 {
+	c, s := math.Cos(a), math.Sin(a)
+	switch axis {
+	case 0:
+		return [3][3]float64{{1, 0, 0}, {0, c, -s}, {0, s, c}}
+	case 1:
+		return [3][3]float64{{c, 0, s}, {0, 1, 0}, {-s, 0, c}}
+	default:
+		return [3][3]float64{{c, -s, 0}, {s, c, 0}, {0, 0, 1}}
+	}
+}
+
+func mulMat3(a, b [3][3]float64) [3][3]float64 {
+	var m [3][3]float64
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			for k := 0; k < 3; k++ {
+				m[r][c] += a[r][k] * b[k][c]
+			}
+		}
+	}
+	return m
+}
+
+func TestEstimatePose(t *testing.T) {
+	tests := []struct {
+		name             string
+		yaw, pitch, roll float64
+		scale            float64
+	}{
+		{name: "frontal", scale: 1},
+		{name: "frontal, small face", scale: 0.3},
+		{name: "turned right", yaw: 30, scale: 1},
+		{name: "turned left", yaw: -45, scale: 2},
+		{name: "looking up", pitch: 20, scale: 1},
+		{name: "looking down", pitch: -25, scale: 1.5},
+		{name: "tilted clockwise", roll: 15, scale: 1},
+		{name: "tilted anticlockwise", roll: -40, scale: 1},
+		{name: "combined", yaw: 25, pitch: -15, roll: 10, scale: 1.2},
+	}
+	const tolerance = 0.5 // degrees
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimatePose(projectPose(tt.yaw, tt.pitch, tt.roll, tt.scale, 320, 240))
+			if math.Abs(float64(got.Yaw)-tt.yaw) > tolerance ||
+				math.Abs(float64(got.Pitch)-tt.pitch) > tolerance ||
+				math.Abs(float64(got.Roll)-tt.roll) > tolerance {
+				t.Errorf("pose = %+v, want yaw %v pitch %v roll %v", got, tt.yaw, tt.pitch, tt.roll)
+			}
+		})
+	}
+}
+
+func TestEstimatePoseTemplate(t *testing.T) {
+	got := EstimatePose(arcfaceTemplate)
+	if math.Abs(float64(got.Yaw)) > 0.5 || math.Abs(float64(got.Pitch)) > 0.5 || math.Abs(float64(got.Roll)) > 0.5 {
+		t.Errorf("pose of the ArcFace template = %+v, want zero", got)
+	}
+}
+
+func TestEstimatePoseDirections(t *testing.T) {
+	// Moving the nose tip of the template shows which way the head turns.
+	nose := func(dx, dy float32) [5][2]float32 {
+		lm := arcfaceTemplate
+		lm[2][0] += dx
+		lm[2][1] += dy
+		return lm
+	}
+	if p := EstimatePose(nose(8, 0)); p.Yaw <= 0 {
+		t.Errorf("nose to the image's right: yaw = %v, want positive", p.Yaw)
+	}
+	if p := EstimatePose(nose(-8, 0)); p.Yaw >= 0 {
+		t.Errorf("nose to the image's left: yaw = %v, want negative", p.Yaw)
+	}
+	if p := EstimatePose(nose(0, -6)); p.Pitch <= 0 {
+		t.Errorf("nose raised: pitch = %v, want positive", p.Pitch)
+	}
+	if p := EstimatePose(nose(0, 6)); p.Pitch >= 0 {
+		t.Errorf("nose lowered: pitch = %v, want negative", p.Pitch)
+	}
+}
+
+func TestEstimatePoseDegenerate(t *testing.T) {
+	var same [5][2]float32
+	for i := range same {
+		same[i] = [2]float32{100, 100}
+	}
+	if p := EstimatePose(same); p.Yaw != 0 || p.Pitch != 0 || p.Roll != 0 {
+		t.Errorf("pose of coincident landmarks = %+v, want zero", p)
+	}
+}
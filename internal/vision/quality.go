@@ -0,0 +1,194 @@
+package vision
+
+import (
+	"image"
+	"math"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+const (
+	qualitySizeRef      = 112   // face side (px) at which size stops improving the score
+	qualitySharpnessRef = 100.0 // Laplacian variance that maps to a sharpness of 0.5
+	qualitySampleSize   = 64    // grid the face is sampled to for sharpness and exposure
+
+	// Head pose angles (degrees) at which the yaw and pitch scores reach 0.
+	qualityYawRange   = 75
+	qualityPitchRange = 60
+
+	// A component below its limit adds the matching reason to FaceQuality.Reasons.
+	qualityMinConfidence = 0.6
+	qualityMinComponent  = 0.5
+	qualityMinSharpness  = 0.3
+)
+
+// AssessQuality scores the face at bbox from the detector confidence, face size,
+// sharpness, exposure, head pose (yaw and pitch solved from the landmarks) and a
+// landmark-layout occlusion check.
+func AssessQuality(img image.Image, bbox [4]float32, landmarks [5][2]float32, confidence float32) models.FaceQuality {
+	gray := sampleGray(img, bbox)
+	pose := EstimatePose(landmarks)
+	yaw, pitch := poseScores(pose)
+	brightness, clipped := exposureStats(gray)
+
+	q := models.FaceQuality{
+		Confidence: clampF(confidence, 0, 1),
+		Size:       clampF(min(bbox[2]-bbox[0], bbox[3]-bbox[1])/qualitySizeRef, 0, 1),
+		Sharpness:  laplacianSharpness(gray),
+		Exposure:   exposureScore(brightness, clipped),
+		Yaw:        yaw,
+		Pitch:      pitch,
+		Occlusion:  occlusionScore(bbox, landmarks),
+		Pose:       pose,
+	}
+	q.Score = q.Occlusion * (0.15*q.Confidence + 0.2*q.Size + 0.25*q.Sharpness + 0.1*q.Exposure + 0.15*q.Yaw + 0.15*q.Pitch)
+
+	if q.Confidence < qualityMinConfidence {
+		q.Reasons = append(q.Reasons, models.QualityLowConfidence)
+	}
+	if q.Size < qualityMinComponent {
+		q.Reasons = append(q.Reasons, models.QualityTooSmall)
+	}
+	if q.Sharpness < qualityMinSharpness {
+		q.Reasons = append(q.Reasons, models.QualityBlurry)
+	}
+	if q.Exposure < qualityMinComponent {
+		if brightness < 128 {
+			q.Reasons = append(q.Reasons, models.QualityUnderexposed)
+		} else {
+			q.Reasons = append(q.Reasons, models.QualityOverexposed)
+		}
+	}
+	if q.Yaw < qualityMinComponent {
+		q.Reasons = append(q.Reasons, models.QualityYaw)
+	}
+	if q.Pitch < qualityMinComponent {
+		q.Reasons = append(q.Reasons, models.QualityPitch)
+	}
+	if q.Occlusion < 1 {
+		q.Reasons = append(q.Reasons, models.QualityOccluded)
+	}
+	return q
+}
+
+// sampleGray samples the bbox region onto a fixed luma grid, so measurements
+// are independent of the face's pixel size. Returns nil for degenerate boxes.
+func sampleGray(img image.Image, bbox [4]float32) *[qualitySampleSize][qualitySampleSize]float64 {
+	w := float64(bbox[2] - bbox[0])
+	h := float64(bbox[3] - bbox[1])
+	if w < 2 || h < 2 {
+		return nil
+	}
+
+	const n = qualitySampleSize
+	var gray [n][n]float64
+	for y := 0; y < n; y++ {
+		sy := int(float64(bbox[1]) + (float64(y)+0.5)*h/n)
+		for x := 0; x < n; x++ {
+			sx := int(float64(bbox[0]) + (float64(x)+0.5)*w/n)
+			r, g, b := rgbAt(img, sx, sy)
+			gray[y][x] = 0.299*r + 0.587*g + 0.114*b
+		}
+	}
+	return &gray
+}
+
+// laplacianSharpness returns the variance of the Laplacian squashed into [0, 1).
+// Blurry and heavily upscaled faces score low.
+func laplacianSharpness(gray *[qualitySampleSize][qualitySampleSize]float64) float32 {
+	if gray == nil {
+		return 0
+	}
+
+	const n = qualitySampleSize
+	var sum, sumSq float64
+	for y := 1; y < n-1; y++ {
+		for x := 1; x < n-1; x++ {
+			lap := gray[y-1][x] + gray[y+1][x] + gray[y][x-1] + gray[y][x+1] - 4*gray[y][x]
+			sum += lap
+			sumSq += lap * lap
+		}
+	}
+	count := float64((n - 2) * (n - 2))
+	mean := sum / count
+	variance := sumSq/count - mean*mean
+
+	return float32(variance / (variance + qualitySharpnessRef))
+}
+
+// exposureStats returns the mean luma and the fraction of clipped (near black
+// or near white) samples.
+func exposureStats(gray *[qualitySampleSize][qualitySampleSize]float64) (mean, clipped float64) {
+	if gray == nil {
+		return 0, 1
+	}
+
+	const n = qualitySampleSize
+	var sum float64
+	var clip int
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			v := gray[y][x]
+			sum += v
+			if v < 10 || v > 245 {
+				clip++
+			}
+		}
+	}
+	return sum / (n * n), float64(clip) / (n * n)
+}
+
+// exposureScore is 1 for a mean luma within [70, 190] and falls off linearly
+// towards black and white; clipped samples reduce it further.
+func exposureScore(mean, clipped float64) float32 {
+	s := 1.0
+	switch {
+	case mean < 70:
+		s = mean / 70
+	case mean > 190:
+		s = (255 - mean) / 65
+	}
+	s *= 1 - math.Min(clipped*2, 1)
+	return clampF(float32(s), 0, 1)
+}
+
+// poseScores rates how directly the face looks at the camera: 1 when frontal,
+// falling linearly to 0 at qualityYawRange and qualityPitchRange degrees. Roll
+// is ignored since alignment removes it.
+func poseScores(pose models.HeadPose) (yaw, pitch float32) {
+	yaw = clampF(1-abs32(pose.Yaw)/qualityYawRange, 0, 1)
+	pitch = clampF(1-abs32(pose.Pitch)/qualityPitchRange, 0, 1)
+	return yaw, pitch
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// occlusionScore checks that the landmarks form a plausible face inside the box:
+// the detector still regresses all five points when part of the face is covered,
+// but they then drift outside the box or out of order. Returns 1 when the layout
+// is consistent and 0.5 otherwise.
+func occlusionScore(bbox [4]float32, lm [5][2]float32) float32 {
+	w := bbox[2] - bbox[0]
+	h := bbox[3] - bbox[1]
+	if w <= 0 || h <= 0 {
+		return 0.5
+	}
+	padX, padY := 0.1*w, 0.1*h
+	for _, p := range lm {
+		if p[0] < bbox[0]-padX || p[0] > bbox[2]+padX || p[1] < bbox[1]-padY || p[1] > bbox[3]+padY {
+			return 0.5
+		}
+	}
+
+	eyeY := (lm[0][1] + lm[1][1]) / 2
+	mouthY := (lm[3][1] + lm[4][1]) / 2
+	if lm[0][0] >= lm[1][0] || lm[3][0] >= lm[4][0] || !(eyeY < lm[2][1] && lm[2][1] < mouthY) {
+		return 0.5
+	}
+	return 1
+}
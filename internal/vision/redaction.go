@@ -0,0 +1,84 @@
+package vision
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/your-org/fd/internal/models"
+	"github.com/your-org/fd/internal/redact"
+)
+
+// redactedFrameKey is where the redacted copy of a raw frame is stored, next
+// to it so frame_retention purges it alike.
+func redactedFrameKey(rawKey string) string {
+	return strings.TrimSuffix(rawKey, ".jpg") + models.RedactedImageSuffix
+}
+
+// redactFrame stores the frame under key with its faces obscured and deletes
+// the raw frame, reporting whether the copy was stored. In unknown mode the
+// faces of tracks with a consolidated identity stay visible.
+func (p *Pipeline) redactFrame(ctx context.Context, rawKey, key string, raw []byte, img image.Image, faces [][4]float32, mode models.RedactionMode, style models.RedactionStyle, updates []TrackUpdate) bool {
+	faces = obscuredFaces(faces, mode, updates)
+	data := raw
+	if len(faces) > 0 {
+		data = encodeJPEG(redact.Faces(img, faces, style), 90)
+	}
+
+	stored := true
+	if err := p.minio.PutObject(ctx, key, data, "image/jpeg"); err != nil {
+		slog.Error("store redacted frame", "error", err, "frame", key)
+		stored = false
+	}
+	// Either way the raw frame must not outlive its processing
+	if err := p.minio.DeleteObject(ctx, rawKey); err != nil {
+		slog.Warn("delete raw frame", "error", err, "frame", rawKey)
+	}
+	return stored
+}
+
+// obscuredFaces returns the faces to obscure in mode: in unknown mode all but
+// those of tracks with a consolidated identity, matched by their box.
+func obscuredFaces(faces [][4]float32, mode models.RedactionMode, updates []TrackUpdate) [][4]float32 {
+	if mode != models.RedactionUnknown {
+		return faces
+	}
+	known := make(map[[4]float32]bool)
+	for _, upd := range updates {
+		if !upd.Ended && upd.Track.PersonID != "" {
+			known[upd.Track.BBox] = true
+		}
+	}
+	return slices.DeleteFunc(slices.Clone(faces), func(b [4]float32) bool { return known[b] })
+}
+
+// DetectFaces returns the face boxes (x1, y1, x2, y2) of a JPEG image, for
+// redacting stored frames on the fly. Safe for concurrent use.
+func (p *Pipeline) DetectFaces(imageData []byte) ([][4]float32, error) {
+	p.embedMu.Lock()
+	defer p.embedMu.Unlock()
+
+	img, err := jpeg.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("decode jpeg: %w", err)
+	}
+
+	bounds := img.Bounds()
+	detTransform := p.detector.Transform(bounds.Dx(), bounds.Dy())
+	detInput := p.detector.preprocess(img, detTransform)
+	detections, err := p.detector.Detect(detInput, detTransform, float32(p.cfg.DetectionThreshold))
+	if err != nil {
+		return nil, fmt.Errorf("detect: %w", err)
+	}
+
+	boxes := make([][4]float32, len(detections))
+	for i, d := range detections {
+		boxes[i] = d.BBox
+	}
+	return boxes, nil
+}
@@ -0,0 +1,46 @@
+package vision
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/your-org/fd/internal/models"
+)
+
+func TestObscuredFaces(t *testing.T) {
+	a := [4]float32{10, 10, 50, 50}
+	b := [4]float32{100, 10, 140, 50}
+	c := [4]float32{200, 10, 240, 50}
+	faces := [][4]float32{a, b, c}
+
+	known := TrackUpdate{Track: &Track{ID: "1", BBox: a, PersonID: "p1"}}
+	unknown := TrackUpdate{Track: &Track{ID: "2", BBox: b}}
+	ended := TrackUpdate{Track: &Track{ID: "3", BBox: c, PersonID: "p2"}, Ended: true}
+	elsewhere := TrackUpdate{Track: &Track{ID: "4", BBox: [4]float32{300, 10, 340, 50}, PersonID: "p3"}}
+
+	tests := []struct {
+		name    string
+		mode    models.RedactionMode
+		updates []TrackUpdate
+		want    [][4]float32
+	}{
+		{name: "off", mode: models.RedactionOff, updates: []TrackUpdate{known}, want: faces},
+		{name: "all keeps known faces", mode: models.RedactionAll, updates: []TrackUpdate{known}, want: faces},
+		{name: "unknown without tracks", mode: models.RedactionUnknown, want: faces},
+		{name: "unknown spares a known face", mode: models.RedactionUnknown, updates: []TrackUpdate{known, unknown}, want: [][4]float32{b, c}},
+		{name: "unknown ignores ended tracks", mode: models.RedactionUnknown, updates: []TrackUpdate{ended}, want: faces},
+		{name: "unknown needs the same box", mode: models.RedactionUnknown, updates: []TrackUpdate{elsewhere}, want: faces},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := slices.Clone(faces)
+			got := obscuredFaces(in, tt.mode, tt.updates)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("obscuredFaces = %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(in, faces) {
+				t.Errorf("obscuredFaces modified its input: %v", in)
+			}
+		})
+	}
+}
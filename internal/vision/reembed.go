@@ -0,0 +1,142 @@
+package vision
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/your-org/fd/internal/models"
+	"github.com/your-org/fd/internal/observability"
+)
+
+// reembedPageSize is the number of stale faces loaded per query.
+const reembedPageSize = 100
+
+// Reembedder re-processes the stored enrollment images (faces/<person_id>/...
+// in MinIO) of faces embedded by another model version, so they become
+// searchable again after the embedder is changed.
+type Reembedder struct {
+	ctx    context.Context
+	p      *Pipeline
+	mu     sync.Mutex
+	status models.ReembedStatus
+}
+
+// NewReembedder returns an idle job that embeds with p. Runs stop when ctx is cancelled.
+func NewReembedder(ctx context.Context, p *Pipeline) *Reembedder {
+	return &Reembedder{
+		ctx:    ctx,
+		p:      p,
+		status: models.ReembedStatus{ModelVersion: p.ModelVersion()},
+	}
+}
+
+// Start launches a run in the background. Returns false if one is already running.
+func (r *Reembedder) Start() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.status.Running {
+		return false
+	}
+
+	now := time.Now()
+	r.status = models.ReembedStatus{
+		Running:      true,
+		ModelVersion: r.p.ModelVersion(),
+		StartedAt:    &now,
+	}
+	go r.run()
+	return true
+}
+
+// Status returns the progress of the current or last run.
+func (r *Reembedder) Status() models.ReembedStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+func (r *Reembedder) run() {
+	version := r.p.ModelVersion()
+	err := r.reembedAll(version)
+
+	r.mu.Lock()
+	now := time.Now()
+	r.status.Running = false
+	r.status.FinishedAt = &now
+	if err != nil {
+		r.status.LastError = err.Error()
+	}
+	st := r.status
+	r.mu.Unlock()
+
+	slog.Info("re-embedding finished", "model_version", version,
+		"updated", st.Updated, "failed", st.Failed, "error", err)
+}
+
+func (r *Reembedder) reembedAll(version string) error {
+	pending, err := r.p.db.CountStaleFaceEmbeddings(r.ctx, version)
+	if err != nil {
+		return fmt.Errorf("count stale faces: %w", err)
+	}
+	r.mu.Lock()
+	r.status.Pending = pending
+	r.mu.Unlock()
+	slog.Info("re-embedding enrollment faces", "model_version", version, "pending", pending)
+
+	// Keyset pagination: failed faces keep their old version, so restarting from
+	// the beginning would return them again.
+	afterID := uuid.Nil
+	for {
+		faces, err := r.p.db.ListStaleFaceEmbeddings(r.ctx, version, afterID, reembedPageSize)
+		if err != nil {
+			return err
+		}
+		if len(faces) == 0 {
+			return nil
+		}
+
+		for _, fe := range faces {
+			if err := r.ctx.Err(); err != nil {
+				return err
+			}
+			err := r.reembedFace(fe, version)
+			r.mu.Lock()
+			r.status.Processed++
+			if err != nil {
+				r.status.Failed++
+				r.status.LastError = err.Error()
+			} else {
+				r.status.Updated++
+			}
+			r.mu.Unlock()
+
+			if err != nil {
+				observability.FacesReembedded.WithLabelValues("failed").Inc()
+				slog.Warn("re-embed face", "face_id", fe.ID, "person_id", fe.PersonID, "error", err)
+			} else {
+				observability.FacesReembedded.WithLabelValues("updated").Inc()
+			}
+		}
+		afterID = faces[len(faces)-1].ID
+	}
+}
+
+func (r *Reembedder) reembedFace(fe models.FaceEmbedding, version string) error {
+	if fe.SourceKey == "" {
+		return fmt.Errorf("no source image stored")
+	}
+	data, err := r.p.minio.GetObject(r.ctx, fe.SourceKey)
+	if err != nil {
+		return fmt.Errorf("load %s: %w", fe.SourceKey, err)
+	}
+	embedding, quality, err := r.p.EmbedImage(data)
+	if err != nil {
+		return err
+	}
+	return r.p.db.UpdateFaceEmbedding(r.ctx, fe.ID, embedding, version, quality.Score)
+}
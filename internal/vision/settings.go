@@ -0,0 +1,76 @@
+package vision
+
+import (
+	"time"
+
+	"github.com/your-org/fd/internal/config"
+	"github.com/your-org/fd/internal/models"
+)
+
+// applyStreamSettings returns the global vision and tracking config with a
+// stream's overrides applied. s may be nil.
+func applyStreamSettings(cfg config.VisionConfig, trackCfg config.TrackingConfig, s *models.StreamSettings) (config.VisionConfig, config.TrackingConfig) {
+	if s == nil {
+		return cfg, trackCfg
+	}
+
+	if s.DetectionThreshold != nil {
+		cfg.DetectionThreshold = *s.DetectionThreshold
+	}
+	if s.RecognitionThreshold != nil {
+		cfg.RecognitionThreshold = *s.RecognitionThreshold
+	}
+	if s.MinFaceSize != nil {
+		cfg.MinFaceSize = *s.MinFaceSize
+	}
+	if s.MinFaceQuality != nil {
+		cfg.MinFaceQuality = *s.MinFaceQuality
+	}
+	if s.MaxYaw != nil {
+		cfg.MaxYaw = *s.MaxYaw
+	}
+	if s.MaxPitch != nil {
+		cfg.MaxPitch = *s.MaxPitch
+	}
+	if s.Liveness != nil {
+		cfg.Liveness = string(*s.Liveness)
+	}
+	if s.LivenessThreshold != nil {
+		cfg.LivenessThreshold = *s.LivenessThreshold
+	}
+	if s.Redaction != nil {
+		cfg.Redaction = string(*s.Redaction)
+	}
+	if s.RedactionStyle != nil {
+		cfg.RedactionStyle = string(*s.RedactionStyle)
+	}
+
+	if s.ReRecognizeInterval != nil {
+		trackCfg.ReRecognizeInterval = time.Duration(*s.ReRecognizeInterval)
+	}
+	if s.MaxAge != nil {
+		trackCfg.MaxAge = *s.MaxAge
+	}
+	if s.MinHits != nil {
+		trackCfg.MinHits = *s.MinHits
+	}
+	if s.IoUThreshold != nil {
+		trackCfg.IoUThreshold = *s.IoUThreshold
+	}
+	if s.ReIDMaxDistance != nil {
+		trackCfg.ReIDMaxDistance = *s.ReIDMaxDistance
+	}
+	if s.BestShotMargin != nil {
+		trackCfg.BestShotMargin = *s.BestShotMargin
+	}
+	if s.IdentityWindow != nil {
+		trackCfg.IdentityWindow = *s.IdentityWindow
+	}
+	if s.IdentityMinVotes != nil {
+		trackCfg.IdentityMinVotes = *s.IdentityMinVotes
+	}
+	if s.IdentitySwitchMargin != nil {
+		trackCfg.IdentitySwitchMargin = *s.IdentitySwitchMargin
+	}
+	return cfg, trackCfg
+}
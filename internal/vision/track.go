@@ -5,137 +5,296 @@ import (
 	"math"
 	"sync"
 	"time"
+
+	"github.com/your-org/fd/internal/config"
+	"github.com/your-org/fd/internal/models"
 )
 
 // Track represents a tracked face across frames.
 type Track struct {
 	ID              string
 	BBox            [4]float32
+	Landmarks       [5][2]float32 // landmarks of the latest matched detection
 	Confidence      float32
-	Age             int           // frames since creation
-	Hits            int           // number of consecutive detections
-	TimeSinceUpdate int           // frames since last detection match
-	Embedding       []float32     // last known embedding
-	LastRecognized  time.Time     // last time recognition was run
-	PersonID        string        // matched person ID, if any
-	MatchScore      float32       // match score
+	Age             int       // frames since creation
+	Hits            int       // number of consecutive detections
+	TimeSinceUpdate int       // frames since last detection match
+	Confirmed       bool      // reached min_hits consecutive detections at least once
+	Embedding       []float32 // last known embedding
+	LastRecognized  time.Time // last time recognition was run
+	PersonID        string    // consolidated identity from VoteIdentity, "" = unknown
+	MatchScore      float32   // mean match score of the consolidated identity's votes
 	Gender          string
 	GenderConf      float32
 	FaceAge         int
 	AgeRange        string
+	AttrSamples     int                   // predictions the smoothed gender/age are based on
+	FaceAttrs       models.FaceAttributes // smoothed labels of the face attribute classifiers
+	FirstSeen       time.Time             // timestamp of the first detection
+	LastSeen        time.Time             // timestamp of the latest matched detection
+	FrameCount      int                   // frames with a matched detection
+	BestQuality     float32               // quality score of the best shot so far
+	BestSnapshotKey string                // MinIO key of the best shot so far
+	BestRedacted    bool                  // the best shot was stored with the face obscured
+
+	started    bool           // track_started has been published
+	votes      []identityVote // recent recognition results, oldest first
+	attrs      attributeSmoother
+	labelSums  map[string][]float64 // per classifier, quality-weighted label probability sums
+	kf         *kalmanBoxFilter     // constant-velocity motion model
+	gallery    [][]float32          // recent embeddings, oldest first, for re-identification
+	firstFrame int                  // tracker frame the track was created in
+	lastFrame  int                  // tracker frame of the latest matched detection
 }
 
-// Tracker implements a simple SORT-like face tracker.
+// Tracker implements SORT: per-track Kalman motion prediction, globally optimal
+// Hungarian assignment on IoU, and min_hits confirmation before a track is emitted.
 type Tracker struct {
-	mu       sync.Mutex
-	tracks   map[string]*Track
-	nextID   int
-	maxAge   int // max frames without detection before track is removed
-	minHits  int // min hits before track is confirmed
-	streamID string
+	mu           sync.Mutex
+	tracks       []*Track // in creation order, so results never depend on map iteration
+	nextID       int
+	maxAge       int     // max frames without detection before track is removed
+	minHits      int     // consecutive hits before a track is confirmed
+	iouThreshold float32 // min IoU between prediction and detection to associate
+	reidMaxDist  float32 // max cosine distance to re-attach a new track to a lost one (0 = off)
+	gallerySize  int     // embeddings kept per track for re-identification
+
+	identityWindow       int     // recognition passes considered by VoteIdentity
+	identityMinVotes     int     // votes a person needs before it is assigned
+	identitySwitchMargin float32 // share of the vote by which a challenger must beat the current person
+	frame                int     // frames seen by Update
+	lastUpdate           time.Time
+	epoch                int64 // tracker creation time, keeps track IDs unique across restarts
+	streamID             string
+	cfg                  config.TrackingConfig // settings the fields above were derived from
 }
 
 // NewTracker creates a new face tracker for a given stream.
-func NewTracker(streamID string, maxAge, minHits int) *Tracker {
-	return &Tracker{
-		tracks:   make(map[string]*Track),
-		maxAge:   maxAge,
-		minHits:  minHits,
-		streamID: streamID,
+func NewTracker(streamID string, cfg config.TrackingConfig) *Tracker {
+	t := &Tracker{
+		lastUpdate: time.Now(),
+		epoch:      time.Now().Unix(),
+		streamID:   streamID,
 	}
+	t.configure(cfg)
+	return t
 }
 
-// Update matches detections to existing tracks and creates new tracks.
-// Returns a list of (track, isNew) pairs for detections that should be processed further.
-func (t *Tracker) Update(detections []Detection) []TrackUpdate {
+// Configure applies changed tracking settings (e.g. a restarted stream with a
+// new per-stream config). Existing tracks are kept.
+func (t *Tracker) Configure(cfg config.TrackingConfig) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-
-	// Increment age for all tracks
-	for _, track := range t.tracks {
-		track.TimeSinceUpdate++
+	if cfg != t.cfg {
+		t.configure(cfg)
 	}
+}
 
-	updates := make([]TrackUpdate, 0, len(detections))
+func (t *Tracker) configure(cfg config.TrackingConfig) {
+	t.cfg = cfg
+	t.maxAge = cfg.MaxAge
+	t.minHits = cfg.MinHits
+	t.iouThreshold = float32(cfg.IoUThreshold)
+	t.reidMaxDist = float32(cfg.ReIDMaxDistance)
+	t.gallerySize = cfg.GallerySize
+	t.identityWindow = cfg.IdentityWindow
+	t.identityMinVotes = cfg.IdentityMinVotes
+	t.identitySwitchMargin = cfg.IdentitySwitchMargin
+}
 
-	// Simple IoU-based matching
-	trackList := make([]*Track, 0, len(t.tracks))
-	for _, tr := range t.tracks {
-		trackList = append(trackList, tr)
-	}
+// Update advances every track by one frame, associates detections with the
+// Kalman-predicted boxes and creates tracks for unmatched detections.
+// It must be called for every processed frame, including frames without faces,
+// so that tracks age out. ts is the frame timestamp.
+// Returned are the confirmed tracks matched in this frame (IsNew on the frame a
+// track becomes confirmed) followed by confirmed tracks dropped after max_age (Ended).
+func (t *Tracker) Update(detections []Detection, ts time.Time) []TrackUpdate {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-	matched := make(map[string]bool)
-	detMatched := make(map[int]bool)
+	t.frame++
+	t.lastUpdate = time.Now()
 
-	// Match detections to tracks by IoU
-	for di, det := range detections {
-		bestIoU := float32(0.3) // min IoU threshold
-		bestTrack := ""
+	// 1. Predict every track forward one frame.
+	predicted := make([][4]float32, len(t.tracks))
+	for i, tr := range t.tracks {
+		predicted[i] = tr.kf.predict()
+		tr.Age++
+		if tr.TimeSinceUpdate > 0 {
+			tr.Hits = 0
+		}
+		tr.TimeSinceUpdate++
+	}
 
-		for _, tr := range trackList {
-			if matched[tr.ID] {
+	// 2. Globally optimal assignment on IoU cost.
+	trackForDet := make([]int, len(detections))
+	for di := range trackForDet {
+		trackForDet[di] = -1
+	}
+	if len(t.tracks) > 0 && len(detections) > 0 {
+		cost := make([][]float64, len(t.tracks))
+		for ti := range t.tracks {
+			cost[ti] = make([]float64, len(detections))
+			for di, det := range detections {
+				cost[ti][di] = 1 - float64(iou(predicted[ti], det.BBox))
+			}
+		}
+		for ti, di := range hungarian(cost) {
+			if di < 0 {
 				continue
 			}
-			iouVal := iou(det.BBox, tr.BBox)
-			if iouVal > bestIoU {
-				bestIoU = iouVal
-				bestTrack = tr.ID
+			if iou(predicted[ti], detections[di].BBox) < t.iouThreshold {
+				continue
 			}
+			trackForDet[di] = ti
+		}
+	}
+
+	updates := make([]TrackUpdate, 0, len(detections))
+
+	// 3. Update matched tracks.
+	for di, ti := range trackForDet {
+		if ti < 0 {
+			continue
 		}
+		det := detections[di]
+		tr := t.tracks[ti]
+		tr.kf.update(det.BBox)
+		tr.BBox = det.BBox
+		tr.Landmarks = det.Landmarks
+		tr.Confidence = det.Confidence
+		tr.Hits++
+		tr.TimeSinceUpdate = 0
+		tr.lastFrame = t.frame
+		tr.LastSeen = ts
+		tr.FrameCount++
 
-		if bestTrack != "" {
-			// Update existing track
-			tr := t.tracks[bestTrack]
-			tr.BBox = det.BBox
-			tr.Confidence = det.Confidence
-			tr.Hits++
-			tr.TimeSinceUpdate = 0
-			matched[bestTrack] = true
-			detMatched[di] = true
-
-			updates = append(updates, TrackUpdate{
-				Track: tr,
-				IsNew: false,
-			})
+		isNew := false
+		if !tr.Confirmed && tr.Hits >= t.minHits {
+			tr.Confirmed = true
+			isNew = true
+		}
+		if tr.Confirmed {
+			updates = append(updates, TrackUpdate{Track: tr, IsNew: isNew})
 		}
 	}
 
-	// Create new tracks for unmatched detections
-	for di, det := range detections {
-		if detMatched[di] {
+	// 4. Create tracks for unmatched detections.
+	for di, ti := range trackForDet {
+		if ti >= 0 {
 			continue
 		}
+		det := detections[di]
 
 		t.nextID++
-		trackID := fmt.Sprintf("%s_%d", t.streamID, t.nextID)
 		tr := &Track{
-			ID:              trackID,
-			BBox:            det.BBox,
-			Confidence:      det.Confidence,
-			Hits:            1,
-			TimeSinceUpdate: 0,
+			ID:         fmt.Sprintf("%s_%d_%d", t.streamID, t.epoch, t.nextID),
+			BBox:       det.BBox,
+			Landmarks:  det.Landmarks,
+			Confidence: det.Confidence,
+			Hits:       1,
+			kf:         newKalmanBoxFilter(det.BBox),
+			firstFrame: t.frame,
+			lastFrame:  t.frame,
+			FirstSeen:  ts,
+			LastSeen:   ts,
+			FrameCount: 1,
 		}
-		t.tracks[trackID] = tr
+		t.tracks = append(t.tracks, tr)
 
-		updates = append(updates, TrackUpdate{
-			Track: tr,
-			IsNew: true,
-		})
+		if t.minHits <= 1 {
+			tr.Confirmed = true
+			updates = append(updates, TrackUpdate{Track: tr, IsNew: true})
+		}
 	}
 
-	// Remove stale tracks
-	for id, tr := range t.tracks {
-		if tr.TimeSinceUpdate > t.maxAge {
-			delete(t.tracks, id)
+	// 5. Remove stale tracks.
+	alive := t.tracks[:0]
+	for _, tr := range t.tracks {
+		if tr.TimeSinceUpdate <= t.maxAge {
+			alive = append(alive, tr)
+		} else if tr.Confirmed {
+			updates = append(updates, TrackUpdate{Track: tr, Ended: true})
 		}
 	}
+	for i := len(alive); i < len(t.tracks); i++ {
+		t.tracks[i] = nil
+	}
+	t.tracks = alive
 
 	return updates
 }
 
+// Reidentify records a fresh embedding for track and, if the track has no
+// appearance history yet, tries to re-attach it to a recently lost track.
+// A lost track qualifies if it was last seen before track appeared and the
+// closest embedding in its gallery is within the configured cosine distance.
+// On a match the lost track takes over the new track's position and state, the
+// new track is dropped, and the surviving track is returned with true.
+// Otherwise track itself is returned with false.
+func (t *Tracker) Reidentify(track *Track, embedding []float32) (*Track, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(track.gallery) > 0 || t.reidMaxDist <= 0 {
+		t.addToGallery(track, embedding)
+		return track, false
+	}
+
+	var best *Track
+	bestDist := t.reidMaxDist
+	for _, cand := range t.tracks {
+		if cand == track || len(cand.gallery) == 0 || cand.lastFrame >= track.firstFrame {
+			continue
+		}
+		for _, g := range cand.gallery {
+			if d := 1 - CosineSimilarity(embedding, g); d <= bestDist {
+				bestDist = d
+				best = cand
+			}
+		}
+	}
+	if best == nil {
+		t.addToGallery(track, embedding)
+		return track, false
+	}
+
+	// The lost track continues where the new one is now.
+	best.BBox = track.BBox
+	best.Landmarks = track.Landmarks
+	best.Confidence = track.Confidence
+	best.Hits = track.Hits
+	best.TimeSinceUpdate = track.TimeSinceUpdate
+	best.Confirmed = best.Confirmed || track.Confirmed
+	best.kf = track.kf
+	best.lastFrame = track.lastFrame
+	best.LastSeen = track.LastSeen
+	best.FrameCount += track.FrameCount
+	t.addToGallery(best, embedding)
+
+	for i, tr := range t.tracks {
+		if tr == track {
+			t.tracks = append(t.tracks[:i], t.tracks[i+1:]...)
+			break
+		}
+	}
+
+	return best, true
+}
+
+func (t *Tracker) addToGallery(track *Track, embedding []float32) {
+	if t.gallerySize <= 0 {
+		return
+	}
+	if len(track.gallery) >= t.gallerySize {
+		track.gallery = append(track.gallery[:0], track.gallery[len(track.gallery)-t.gallerySize+1:]...)
+	}
+	track.gallery = append(track.gallery, embedding)
+}
+
 // ShouldRecognize returns true if recognition should be run for this track.
 func (t *Tracker) ShouldRecognize(track *Track, interval time.Duration) bool {
-	if track.Hits < t.minHits {
+	if !track.Confirmed {
 		return false
 	}
 	if track.Embedding == nil {
@@ -154,6 +313,35 @@ func (t *Tracker) TrackCount() int {
 type TrackUpdate struct {
 	Track *Track
 	IsNew bool
+	Ended bool // track expired after max_age frames without a detection
+}
+
+// Flush removes every track, e.g. because the stream stopped sending frames.
+// Returns the confirmed tracks that ended.
+func (t *Tracker) Flush() []*Track {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var ended []*Track
+	for _, tr := range t.tracks {
+		if tr.Confirmed {
+			ended = append(ended, tr)
+		}
+	}
+	t.tracks = nil
+	return ended
+}
+
+// Idle reports whether the tracker has had no updates for at least d.
+func (t *Tracker) Idle(d time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return time.Since(t.lastUpdate) >= d
+}
+
+// Dwell returns how long the track has been visible.
+func (tr *Track) Dwell() time.Duration {
+	return tr.LastSeen.Sub(tr.FirstSeen)
 }
 
 // CosineSimilarity computes cosine similarity between two normalized vectors.
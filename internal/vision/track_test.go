@@ -0,0 +1,271 @@
+package vision
+
+import (
+	"testing"
+	"time"
+
+	"github.com/your-org/fd/internal/config"
+)
+
+func box(x, y float32) Detection {
+	return Detection{BBox: [4]float32{x, y, x + 40, y + 40}, Confidence: 0.9}
+}
+
+func newTestTracker(minHits, maxAge int) *Tracker {
+	return NewTracker("s", config.TrackingConfig{MaxAge: maxAge, MinHits: minHits, IoUThreshold: 0.3, ReIDMaxDistance: -1})
+}
+
+func TestTrackerConfirmation(t *testing.T) {
+	tests := []struct {
+		name       string
+		minHits    int
+		detected   []bool // per frame, whether the face is detected
+		wantNewAt  int    // frame (0-based) of the IsNew update, -1 = never
+		wantUpdate []bool // per frame, whether the track is reported
+	}{
+		{name: "min_hits 1", minHits: 1, detected: []bool{true, true}, wantNewAt: 0, wantUpdate: []bool{true, true}},
+		{name: "min_hits 3", minHits: 3, detected: []bool{true, true, true, true}, wantNewAt: 2, wantUpdate: []bool{false, false, true, true}},
+		{name: "hits must be consecutive", minHits: 3, detected: []bool{true, true, false, true, true, true},
+			wantNewAt: 5, wantUpdate: []bool{false, false, false, false, false, true}},
+		{name: "never confirmed", minHits: 3, detected: []bool{true, true}, wantNewAt: -1, wantUpdate: []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTracker(tt.minHits, 5)
+			newAt := -1
+			for i, det := range tt.detected {
+				var dets []Detection
+				if det {
+					dets = []Detection{box(100, 100)}
+				}
+				reported := false
+				for _, upd := range tr.Update(dets, time.Now()) {
+					if upd.Ended {
+						continue
+					}
+					reported = true
+					if upd.IsNew {
+						if newAt >= 0 {
+							t.Fatalf("frame %d: IsNew again after frame %d", i, newAt)
+						}
+						newAt = i
+					}
+				}
+				if reported != tt.wantUpdate[i] {
+					t.Errorf("frame %d: reported = %v, want %v", i, reported, tt.wantUpdate[i])
+				}
+			}
+			if newAt != tt.wantNewAt {
+				t.Errorf("IsNew at frame %d, want %d", newAt, tt.wantNewAt)
+			}
+		})
+	}
+}
+
+func TestTrackerMaxAge(t *testing.T) {
+	const maxAge = 3
+	tests := []struct {
+		name      string
+		missed    int // frames without the face before it is detected again
+		wantSame  bool
+		wantCount int // tracks after the missed frames
+	}{
+		{name: "missed one frame", missed: 1, wantSame: true, wantCount: 1},
+		{name: "missed max_age frames", missed: maxAge, wantSame: true, wantCount: 1},
+		{name: "missed more than max_age", missed: maxAge + 1, wantSame: false, wantCount: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTracker(1, maxAge)
+			first := tr.Update([]Detection{box(100, 100)}, time.Now())[0].Track
+			for i := 0; i < tt.missed; i++ {
+				tr.Update(nil, time.Now())
+			}
+			if n := tr.TrackCount(); n != tt.wantCount {
+				t.Fatalf("%d tracks, want %d", n, tt.wantCount)
+			}
+			var again *Track
+			for _, u := range tr.Update([]Detection{box(100, 100)}, time.Now()) {
+				if !u.Ended {
+					again = u.Track
+				}
+			}
+			if (again == first) != tt.wantSame {
+				t.Errorf("face continued its track: %v, want %v", again == first, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestTrackerEnded(t *testing.T) {
+	tests := []struct {
+		name      string
+		seen      int // frames the face is detected before it disappears
+		wantEnded bool
+	}{
+		{name: "confirmed track", seen: 3, wantEnded: true},
+		{name: "tentative track", seen: 2, wantEnded: false},
+	}
+	const maxAge = 2
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTracker(3, maxAge)
+			for i := 0; i < tt.seen; i++ {
+				tr.Update([]Detection{box(100, 100)}, time.Now())
+			}
+			ended := 0
+			for i := 0; i <= maxAge; i++ {
+				for _, upd := range tr.Update(nil, time.Now()) {
+					if !upd.Ended {
+						t.Fatalf("update without a detection: %+v", upd)
+					}
+					if i < maxAge {
+						t.Fatalf("ended after %d empty frames, want %d", i+1, maxAge+1)
+					}
+					ended++
+				}
+			}
+			if (ended == 1) != tt.wantEnded || ended > 1 {
+				t.Errorf("ended %d times, want ended: %v", ended, tt.wantEnded)
+			}
+			if n := tr.TrackCount(); n != 0 {
+				t.Errorf("%d tracks left, want 0", n)
+			}
+		})
+	}
+}
+
+func TestTrackerAssignment(t *testing.T) {
+	tr := newTestTracker(1, 5)
+	ids := func(upds []TrackUpdate) map[float32]string {
+		m := make(map[float32]string)
+		for _, u := range upds {
+			m[u.Track.BBox[0]] = u.Track.ID
+		}
+		return m
+	}
+
+	// Two overlapping faces; a steps left and b steps onto most of a's old box.
+	// The best single pair is a's track with b's detection (IoU 0.82), after
+	// which b's track overlaps nothing above iou_threshold: greedy IoU matching
+	// would swap a's identity to b and start a new track. The optimal assignment
+	// keeps both (IoU 0.43 and 0.54).
+	first := ids(tr.Update([]Detection{
+		{BBox: [4]float32{100, 0, 200, 100}},
+		{BBox: [4]float32{140, 0, 240, 100}},
+	}, time.Now()))
+	second := ids(tr.Update([]Detection{
+		{BBox: [4]float32{60, 0, 160, 100}},
+		{BBox: [4]float32{110, 0, 210, 100}},
+	}, time.Now()))
+	if second[60] != first[100] || second[110] != first[140] {
+		t.Errorf("tracks %v became %v, want a (x=100) at x=60 and b (x=140) at x=110", first, second)
+	}
+	if n := tr.TrackCount(); n != 2 {
+		t.Errorf("%d tracks, want 2", n)
+	}
+}
+
+func TestTrackerCrossingPaths(t *testing.T) {
+	tr := newTestTracker(1, 5)
+
+	// a walks right and b walks left along the same line, crossing halfway.
+	// Without motion prediction the tracks swap faces once the boxes have
+	// passed each other's last position.
+	var aID, bID string
+	for i := 0; i <= 20; i++ {
+		ax, bx := float32(10*i), float32(200-10*i)
+		upds := tr.Update([]Detection{box(ax, 100), box(bx, 100)}, time.Now())
+		if len(upds) != 2 {
+			t.Fatalf("frame %d: %d updates, want 2", i, len(upds))
+		}
+		if i == 0 {
+			aID, bID = upds[0].Track.ID, upds[1].Track.ID
+			continue
+		}
+		if i == 10 {
+			continue // both at the same spot
+		}
+		for _, u := range upds {
+			want := aID
+			if u.Track.BBox[0] == bx {
+				want = bID
+			}
+			if u.Track.ID != want {
+				t.Fatalf("frame %d: face at x=%v has track %s, want %s", i, u.Track.BBox[0], u.Track.ID, want)
+			}
+		}
+	}
+}
+
+func TestTrackerReidentify(t *testing.T) {
+	face := []float32{1, 0, 0}
+	sameFace := []float32{0.96, 0.28, 0} // cosine distance 0.04
+	otherFace := []float32{0, 1, 0}      // cosine distance 1
+
+	tests := []struct {
+		name        string
+		maxDist     float64
+		overlapping bool // the lost track is still seen when the new one appears
+		embedding   []float32
+		want        bool
+	}{
+		{name: "same face returns", maxDist: 0.45, embedding: sameFace, want: true},
+		{name: "other face", maxDist: 0.45, embedding: otherFace, want: false},
+		{name: "beyond max distance", maxDist: 0.03, embedding: sameFace, want: false},
+		{name: "seen at the same time", maxDist: 0.45, overlapping: true, embedding: sameFace, want: false},
+		{name: "disabled", maxDist: -1, embedding: sameFace, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTracker("s", config.TrackingConfig{
+				MaxAge: 10, MinHits: 1, IoUThreshold: 0.3, ReIDMaxDistance: tt.maxDist, GallerySize: 5,
+			})
+
+			lost := tr.Update([]Detection{box(0, 0)}, time.Now())[0].Track
+			if got, ok := tr.Reidentify(lost, face); ok || got != lost {
+				t.Fatalf("first track re-attached to %s", got.ID)
+			}
+			if !tt.overlapping {
+				tr.Update(nil, time.Now()) // the face is lost
+			}
+
+			dets := []Detection{box(300, 300)}
+			if tt.overlapping {
+				dets = append(dets, box(0, 0))
+			}
+			var fresh *Track
+			for _, u := range tr.Update(dets, time.Now()) {
+				if u.Track != lost {
+					fresh = u.Track
+				}
+			}
+			if fresh == nil {
+				t.Fatal("no new track for the returning face")
+			}
+
+			got, ok := tr.Reidentify(fresh, tt.embedding)
+			if ok != tt.want {
+				t.Fatalf("re-attached = %v, want %v", ok, tt.want)
+			}
+			if !tt.want {
+				if got != fresh {
+					t.Errorf("returned track %s, want the new track %s", got.ID, fresh.ID)
+				}
+				return
+			}
+			if got != lost {
+				t.Fatalf("re-attached to %s, want the lost track %s", got.ID, lost.ID)
+			}
+			if got.BBox != box(300, 300).BBox {
+				t.Errorf("lost track at %v, want it moved to the new track's box", got.BBox)
+			}
+			if n := tr.TrackCount(); n != 1 {
+				t.Errorf("%d tracks, want the new track dropped", n)
+			}
+			if upds := tr.Update([]Detection{box(305, 300)}, time.Now()); len(upds) != 1 || upds[0].Track != lost {
+				t.Errorf("next frame updated %+v, want the lost track to continue", upds)
+			}
+		})
+	}
+}
@@ -3,21 +3,48 @@ package dto
 import "github.com/google/uuid"
 
 type EventResponse struct {
-	ID               uuid.UUID  `json:"id"`
-	StreamID         uuid.UUID  `json:"stream_id"`
-	TrackID          string     `json:"track_id"`
-	Timestamp        string     `json:"timestamp"`
-	Gender           string     `json:"gender"`
-	GenderConfidence float32    `json:"gender_confidence"`
-	Age              int        `json:"age"`
-	AgeRange         string     `json:"age_range"`
-	Confidence       float32    `json:"confidence"`
-	MatchedPersonID  *uuid.UUID `json:"matched_person_id,omitempty"`
-	MatchedName      string     `json:"matched_name,omitempty"`
-	MatchScore       float32    `json:"match_score,omitempty"`
-	SnapshotURL      string     `json:"snapshot_url,omitempty"`
-	FrameURL         string     `json:"frame_url,omitempty"`
-	CreatedAt        string     `json:"created_at"`
+	ID               uuid.UUID                `json:"id"`
+	StreamID         uuid.UUID                `json:"stream_id"`
+	TrackID          string                   `json:"track_id"`
+	Timestamp        string                   `json:"timestamp"`
+	BBox             []float32                `json:"bbox,omitempty"`      // x1, y1, x2, y2 in frame pixels
+	Landmarks        []float32                `json:"landmarks,omitempty"` // x, y of the left eye, right eye, nose tip, left and right mouth corner
+	Gender           string                   `json:"gender"`
+	GenderConfidence float32                  `json:"gender_confidence"`
+	Age              int                      `json:"age"`
+	AgeRange         string                   `json:"age_range"`
+	AttributeSamples int                      `json:"attribute_samples"`
+	Confidence       float32                  `json:"confidence"`
+	MatchedPersonID  *uuid.UUID               `json:"matched_person_id,omitempty"`
+	MatchedName      string                   `json:"matched_name,omitempty"`
+	MatchScore       float32                  `json:"match_score,omitempty"`
+	InstantPersonID  *uuid.UUID               `json:"instant_person_id,omitempty"`
+	InstantScore     float32                  `json:"instant_score,omitempty"`
+	QualityScore     float32                  `json:"quality_score"`
+	ModelVersion     string                   `json:"model_version"`
+	VisitorID        *uuid.UUID               `json:"visitor_id,omitempty"`
+	LivenessScore    *float32                 `json:"liveness_score,omitempty"`
+	Spoof            bool                     `json:"spoof,omitempty"`
+	Attributes       map[string]FaceAttribute `json:"attributes,omitempty"`
+	Pose             *HeadPose                `json:"pose,omitempty"`
+	SnapshotURL      string                   `json:"snapshot_url,omitempty"`
+	FrameURL         string                   `json:"frame_url,omitempty"`
+	CreatedAt        string                   `json:"created_at"`
+}
+
+// HeadPose is the face's orientation in degrees: yaw positive turned towards the
+// image's right, pitch positive looking up, roll positive tilted clockwise.
+type HeadPose struct {
+	Yaw   float32 `json:"yaw"`
+	Pitch float32 `json:"pitch"`
+	Roll  float32 `json:"roll"`
+}
+
+// FaceAttribute is the label a face attribute classifier (mask, eyewear, hat, ...) gave the track.
+type FaceAttribute struct {
+	Label      string  `json:"label"`
+	Confidence float32 `json:"confidence"`
+	Occluding  bool    `json:"occluding,omitempty"`
 }
 
 type EventListResponse struct {
@@ -48,10 +75,37 @@ type EventSearchResult struct {
 	SnapshotURL     string     `json:"snapshot_url,omitempty"`
 }
 
+// TrackResponse is the lifecycle summary of one tracked face.
+type TrackResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	StreamID     uuid.UUID  `json:"stream_id"`
+	TrackID      string     `json:"track_id"`
+	Status       string     `json:"status"` // active, ended
+	FirstSeen    string     `json:"first_seen"`
+	LastSeen     string     `json:"last_seen"`
+	DwellSeconds float64    `json:"dwell_seconds"`
+	FrameCount   int        `json:"frame_count"`
+	PersonID     *uuid.UUID `json:"person_id,omitempty"`
+	MatchScore   float32    `json:"match_score,omitempty"`
+	// Gender and age smoothed over the track's recognition passes.
+	Gender           string  `json:"gender"`
+	GenderConfidence float32 `json:"gender_confidence"`
+	Age              int     `json:"age"`
+	AgeRange         string  `json:"age_range"`
+	AttributeSamples int     `json:"attribute_samples"`
+}
+
+type TrackListResponse struct {
+	Tracks []TrackResponse `json:"tracks"`
+	Total  int             `json:"total"`
+}
+
 // WSEvent is a WebSocket message for real-time event delivery.
+// Detection events carry Data; track lifecycle events carry Track.
 type WSEvent struct {
-	Type     string        `json:"type"` // face_detected, face_recognized, stream_status
-	StreamID uuid.UUID     `json:"stream_id"`
-	Data     EventResponse `json:"data,omitempty"`
-	Status   string        `json:"status,omitempty"`
+	Type     string         `json:"type"` // face_detected, face_recognized, track_started, track_updated, track_ended, stream_status
+	StreamID uuid.UUID      `json:"stream_id"`
+	Data     *EventResponse `json:"data,omitempty"`
+	Track    *TrackResponse `json:"track,omitempty"`
+	Status   string         `json:"status,omitempty"`
 }
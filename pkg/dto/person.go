@@ -34,11 +34,13 @@ type PersonResponse struct {
 }
 
 type FaceEmbeddingResponse struct {
-	ID        uuid.UUID `json:"id"`
-	PersonID  uuid.UUID `json:"person_id"`
-	Quality   float32   `json:"quality"`
-	SourceKey string    `json:"source_key"`
-	CreatedAt string    `json:"created_at"`
+	ID             uuid.UUID `json:"id"`
+	PersonID       uuid.UUID `json:"person_id"`
+	Quality        float32   `json:"quality"`
+	QualityReasons []string  `json:"quality_reasons,omitempty"` // only on upload
+	ModelVersion   string    `json:"model_version"`
+	SourceKey      string    `json:"source_key"`
+	CreatedAt      string    `json:"created_at"`
 }
 
 type SearchRequest struct {
@@ -33,3 +33,71 @@ type StreamListResponse struct {
 	Streams []StreamResponse `json:"streams"`
 	Total   int              `json:"total"`
 }
+
+// StreamRegions is the body of PUT /v1/streams/:id/regions and its response.
+// Points are [x, y] normalised to the frame (0..1).
+type StreamRegions struct {
+	ROI     [][][2]float64 `json:"roi"`
+	Exclude [][][2]float64 `json:"exclude"`
+}
+
+// StreamAnalyticsResponse is the body of GET /v1/streams/:id/analytics.
+type StreamAnalyticsResponse struct {
+	StreamID uuid.UUID       `json:"stream_id"`
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Interval string          `json:"interval"`
+	Lines    []LineAnalytics `json:"lines"`
+	Zones    []ZoneAnalytics `json:"zones"`
+}
+
+// LineAnalytics holds the crossings of one tripwire, in total and per bucket.
+type LineAnalytics struct {
+	Name    string       `json:"name"`
+	In      int          `json:"in"`
+	Out     int          `json:"out"`
+	Buckets []LineBucket `json:"buckets"`
+}
+
+type LineBucket struct {
+	Start string `json:"start"`
+	In    int    `json:"in"`
+	Out   int    `json:"out"`
+}
+
+// ZoneAnalytics holds the entries, exits and occupancy of one zone, in total
+// and per bucket. Occupancy is the value after the last change in the window.
+type ZoneAnalytics struct {
+	Name          string       `json:"name"`
+	Entries       int          `json:"entries"`
+	Exits         int          `json:"exits"`
+	Occupancy     int          `json:"occupancy"`
+	PeakOccupancy int          `json:"peak_occupancy"`
+	Buckets       []ZoneBucket `json:"buckets"`
+}
+
+type ZoneBucket struct {
+	Start         string `json:"start"`
+	Entries       int    `json:"entries"`
+	Exits         int    `json:"exits"`
+	Occupancy     int    `json:"occupancy"`
+	PeakOccupancy int    `json:"peak_occupancy"`
+}
+
+// AttributeAnalyticsResponse is the body of GET /v1/analytics/attributes.
+type AttributeAnalyticsResponse struct {
+	Name     string            `json:"name"`
+	StreamID *uuid.UUID        `json:"stream_id,omitempty"` // absent: all streams
+	From     string            `json:"from"`
+	To       string            `json:"to"`
+	Interval string            `json:"interval"` // hour or day
+	Timezone string            `json:"timezone"`
+	Totals   map[string]int    `json:"totals"` // bucket counts summed per label
+	Buckets  []AttributeBucket `json:"buckets"`
+}
+
+// AttributeBucket counts the tracks per label of one hour or day.
+type AttributeBucket struct {
+	Start  string         `json:"start"`
+	Tracks map[string]int `json:"tracks"`
+}
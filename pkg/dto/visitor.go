@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// VisitorResponse is an anonymous identity built from unknown tracks.
+type VisitorResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	ModelVersion string     `json:"model_version"`
+	TrackCount   int        `json:"track_count"`
+	EventCount   int        `json:"event_count"`
+	FirstSeen    string     `json:"first_seen"`
+	LastSeen     string     `json:"last_seen"`
+	PersonID     *uuid.UUID `json:"person_id,omitempty"`
+	SnapshotURLs []string   `json:"snapshot_urls"`
+	CreatedAt    string     `json:"created_at"`
+}
+
+type VisitorListResponse struct {
+	Visitors []VisitorResponse `json:"visitors"`
+	Total    int               `json:"total"`
+}
+
+// PromoteVisitorRequest creates a person from a visitor.
+type PromoteVisitorRequest struct {
+	CollectionID uuid.UUID       `json:"collection_id" binding:"required"`
+	Name         string          `json:"name" binding:"required"`
+	Metadata     json.RawMessage `json:"metadata,omitempty"`
+}
+
+// VisitorAnalyticsResponse is the body of GET /v1/analytics/visitors.
+type VisitorAnalyticsResponse struct {
+	StreamID  *uuid.UUID         `json:"stream_id,omitempty"` // absent: all streams
+	From      string             `json:"from"`
+	To        string             `json:"to"`
+	Interval  string             `json:"interval"` // hour or day
+	Timezone  string             `json:"timezone"`
+	Buckets   []FootfallBucket   `json:"buckets"`
+	MinDays   int                `json:"min_days"`
+	Returning []ReturningVisitor `json:"returning"`
+}
+
+// FootfallBucket counts the unique people of one hour or day.
+type FootfallBucket struct {
+	Start          string `json:"start"`
+	UniqueVisitors int    `json:"unique_visitors"`
+	Known          int    `json:"known"`
+	Unknown        int    `json:"unknown"`
+	Tracks         int    `json:"tracks"`
+}
+
+// ReturningVisitor is a visitor seen on at least min_days distinct days.
+type ReturningVisitor struct {
+	VisitorID uuid.UUID  `json:"visitor_id"`
+	PersonID  *uuid.UUID `json:"person_id,omitempty"`
+	Days      int        `json:"days"`
+	Tracks    int        `json:"tracks"`
+	FirstSeen string     `json:"first_seen"`
+	LastSeen  string     `json:"last_seen"`
+}